@@ -0,0 +1,271 @@
+package game
+
+import (
+    "strings"
+)
+
+// Directions (row, col) a sliding piece can move along
+var (
+    rookDirections   = [][2]int{{-1, 0}, {1, 0}, {0, -1}, {0, 1}}
+    bishopDirections = [][2]int{{-1, -1}, {-1, 1}, {1, -1}, {1, 1}}
+    knightOffsets    = [][2]int{{-2, -1}, {-2, 1}, {-1, -2}, {-1, 2}, {1, -2}, {1, 2}, {2, -1}, {2, 1}}
+)
+
+// Return true if the square at the given position is attacked by any piece of byTeam.
+// Unlike getMoves(), pawns only attack diagonally (whether or not the square is occupied).
+func (board Board) IsSquareAttacked(position string, byTeam Team) bool {
+    square := board.getSquare(position)
+    if square == nil {
+        return false
+    }
+    return board.isAttackedAt(square.row, square.col, byTeam)
+}
+
+// Check if (row, col) is attacked by byTeam.
+// Sliding pieces see through the transparent squares (used to lift the King off the board when checking its escapes)
+func (board Board) isAttackedAt(row, col int, byTeam Team, transparent ...*Square) bool {
+
+    //Pawns: white pawns move up (row--), so they attack from the row below
+    pawnRow := row + 1
+    if byTeam == black {
+        pawnRow = row - 1
+    }
+    for _, dc := range []int{-1, 1} {
+        if board.hasPieceAt(pawnRow, col+dc, byTeam, "p") {
+            return true
+        }
+    }
+
+    //Knights
+    for _, offset := range knightOffsets {
+        if board.hasPieceAt(row+offset[0], col+offset[1], byTeam, "n") {
+            return true
+        }
+    }
+
+    //King
+    for i := -1; i <= 1; i++ {
+        for j := -1; j <= 1; j++ {
+            if (i != 0 || j != 0) && board.hasPieceAt(row+i, col+j, byTeam, "k") {
+                return true
+            }
+        }
+    }
+
+    //Sliding pieces
+    for _, direction := range rookDirections {
+        piece := board.firstPieceAlong(row, col, direction, transparent...)
+        if piece != nil && piece.team == byTeam && (piece.kind() == "r" || piece.kind() == "q") {
+            return true
+        }
+    }
+    for _, direction := range bishopDirections {
+        piece := board.firstPieceAlong(row, col, direction, transparent...)
+        if piece != nil && piece.team == byTeam && (piece.kind() == "b" || piece.kind() == "q") {
+            return true
+        }
+    }
+
+    return false
+}
+
+// Get the opponent pieces currently giving check to the team's King
+func (board Board) getCheckers(team Team) []Piece {
+    var checkers []Piece
+    king := board.getSquare(board.getKingPosition(team))
+    opponent := getOpponentTeam(team)
+
+    for _, piece := range board.getAllPieces(opponent) {
+        if board.attacksSquare(piece, king.row, king.col) {
+            checkers = append(checkers, piece)
+        }
+    }
+    return checkers
+}
+
+// Get the team's pieces pinned to their King, mapped from position to the (row, col) direction of the pin
+func (board Board) getPins(team Team) map[string][2]int {
+    pins := make(map[string][2]int)
+    king := board.getSquare(board.getKingPosition(team))
+
+    findPins := func(directions [][2]int, sliderKind string) {
+        for _, direction := range directions {
+            var pinned *Piece
+            for i, j := king.row+direction[0], king.col+direction[1]; isOnBoard(i, j); i, j = i+direction[0], j+direction[1] {
+                piece := board.squares[i][j].getPiece()
+                if piece == nil {
+                    continue
+                }
+                if pinned == nil && piece.team == team {
+                    pinned = piece
+                    continue
+                }
+                if pinned != nil && piece.team != team && (piece.kind() == sliderKind || piece.kind() == "q") {
+                    pins[getCoordinatePosition(pinned.row, pinned.col)] = direction
+                }
+                break
+            }
+        }
+    }
+    findPins(rookDirections, "r")
+    findPins(bishopDirections, "b")
+
+    return pins
+}
+
+// Get all legal moves ("e2 e4") of the team, using checkers and pins instead of trying every move
+func (board Board) getLegalMoves(team Team) []string {
+    var moves []string
+
+    kingPosition := board.getKingPosition(team)
+    king := board.getSquare(kingPosition)
+    opponent := getOpponentTeam(team)
+
+    //King moves: the King itself must not shadow the squares behind it along a slider ray
+    for _, positionTo := range getMoves(board, *king.piece) {
+        squareTo := board.getSquare(positionTo)
+        if !board.isAttackedAt(squareTo.row, squareTo.col, opponent, king) {
+            moves = append(moves, kingPosition+" "+positionTo)
+        }
+    }
+
+    checkers := board.getCheckers(team)
+    if len(checkers) > 1 {
+        return moves //Double check, only the King can move
+    }
+
+    //Squares a non-King piece may move to in order to resolve a single check
+    var resolvingSquares map[string]bool
+    if len(checkers) == 1 {
+        resolvingSquares = board.getCheckResolvingSquares(king, checkers[0])
+    }
+
+    pins := board.getPins(team)
+
+    for _, piece := range board.getAllPieces(team) {
+        if isKing(piece) {
+            continue
+        }
+        positionFrom := getCoordinatePosition(piece.row, piece.col)
+        pinDirection, pinned := pins[positionFrom]
+
+        for _, positionTo := range getMoves(board, piece) {
+            squareTo := board.getSquare(positionTo)
+            if pinned && !isOnLine(king.row, king.col, squareTo.row, squareTo.col, pinDirection) {
+                continue
+            }
+            if resolvingSquares != nil && !resolvingSquares[positionTo] {
+                continue
+            }
+            moves = append(moves, positionFrom+" "+positionTo)
+        }
+    }
+
+    for _, castling := range board.getCastlingMoves(team) {
+        moves = append(moves, board.getCastlingCommand(castling))
+    }
+    return moves
+}
+
+// Get the squares that capture the checker or block its ray towards the King
+func (board Board) getCheckResolvingSquares(king *Square, checker Piece) map[string]bool {
+    squares := map[string]bool{getCoordinatePosition(checker.row, checker.col): true}
+
+    kind := checker.kind()
+    if kind != "r" && kind != "b" && kind != "q" {
+        return squares //Knight and Pawn checks cannot be blocked
+    }
+
+    rowStep := sign(checker.row - king.row)
+    colStep := sign(checker.col - king.col)
+    for i, j := king.row+rowStep, king.col+colStep; i != checker.row || j != checker.col; i, j = i+rowStep, j+colStep {
+        squares[getCoordinatePosition(i, j)] = true
+    }
+    return squares
+}
+
+// Check if the piece attacks (row, col) on the current board
+func (board Board) attacksSquare(piece Piece, row, col int) bool {
+    dRow := row - piece.row
+    dCol := col - piece.col
+
+    switch piece.kind() {
+    case "p":
+        forward := -1
+        if piece.team == black {
+            forward = 1
+        }
+        return dRow == forward && (dCol == 1 || dCol == -1)
+    case "n":
+        return dRow*dRow+dCol*dCol == 5
+    case "k":
+        return dRow*dRow <= 1 && dCol*dCol <= 1 && (dRow != 0 || dCol != 0)
+    }
+
+    straight := dRow == 0 || dCol == 0
+    diagonal := dRow == dCol || dRow == -dCol
+    if (dRow == 0 && dCol == 0) ||
+        (piece.kind() == "r" && !straight) ||
+        (piece.kind() == "b" && !diagonal) ||
+        (piece.kind() == "q" && !straight && !diagonal) {
+        return false
+    }
+
+    direction := [2]int{sign(dRow), sign(dCol)}
+    target := board.firstPieceAlong(piece.row, piece.col, direction)
+    return target != nil && target.row == row && target.col == col
+}
+
+// Walk from (row, col) along the direction and return the first piece met, nil if none
+func (board Board) firstPieceAlong(row, col int, direction [2]int, transparent ...*Square) *Piece {
+    for i, j := row+direction[0], col+direction[1]; isOnBoard(i, j); i, j = i+direction[0], j+direction[1] {
+        square := &board.squares[i][j]
+        if square.hasPiece() && !containsSquare(transparent, square) {
+            return square.getPiece()
+        }
+    }
+    return nil
+}
+
+func containsSquare(squares []*Square, square *Square) bool {
+    for _, element := range squares {
+        if element == square {
+            return true
+        }
+    }
+    return false
+}
+
+func (board Board) hasPieceAt(row, col int, team Team, kind string) bool {
+    if !isOnBoard(row, col) {
+        return false
+    }
+    piece := board.squares[row][col].getPiece()
+    return piece != nil && piece.team == team && piece.kind() == kind
+}
+
+// MARK: Helper functions
+func isOnBoard(row, col int) bool {
+    return row >= 0 && row < boardSize && col >= 0 && col < boardSize
+}
+
+// Check if (row, col) lies on the line through (originRow, originCol) with the given direction
+func isOnLine(originRow, originCol, row, col int, direction [2]int) bool {
+    return (row-originRow)*direction[1] == (col-originCol)*direction[0]
+}
+
+func sign(n int) int {
+    switch {
+    case n > 0:
+        return 1
+    case n < 0:
+        return -1
+    default:
+        return 0
+    }
+}
+
+// Lower case sign of the piece ("k", "q", "r", "b", "n" or "p") regardless of its team
+func (piece Piece) kind() string {
+    return strings.ToLower(piece.sign)
+}
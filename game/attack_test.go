@@ -0,0 +1,120 @@
+package game
+
+import (
+    "sort"
+    "strings"
+    "testing"
+)
+
+// Count the leaf nodes of the legal move tree to the depth
+func perft(board *Board, team Team, depth int) int {
+    moves := board.getLegalMoves(team)
+    if depth == 1 {
+        return len(moves)
+    }
+    nodes := 0
+    for _, command := range moves {
+        undo := board.makeMove(board.parseCommand(command, team))
+        nodes += perft(board, getOpponentTeam(team), depth-1)
+        board.unmakeMove(undo)
+    }
+    return nodes
+}
+
+func newTestGame(t *testing.T, fen string) ChessGame {
+    game, err := NewFromFEN(fen)
+    if err != nil {
+        t.Fatalf("%s: %v", fen, err)
+    }
+    return game
+}
+
+// Reference counts from chessprogramming.org/Perft_Results and its Chess960 page, at depths without en passant or promotion
+func TestPerft(t *testing.T) {
+    tests := []struct {
+        name  string
+        fen   string
+        nodes []int //by depth, from 1
+    }{
+        {"start", StartFEN, []int{20, 400, 8902}},
+        {"position 3", "8/2p5/3p4/KP5r/1R3p1k/8/4P1P1/8 w - - 0 1", []int{14, 191}}, //2812 at depth 3 has 2 en passant captures
+        {"kiwipete", "r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1", []int{48}}, //2 castlings, 2039 at depth 2 has an en passant capture
+        {"chess960 1", "bqnb1rkr/pp3ppp/3ppn2/2p5/5P2/P2P4/NPP1P1PP/BQ1BNRKR w HFhf - 2 9", []int{21, 528}},
+    }
+
+    for _, test := range tests {
+        game := newTestGame(t, test.fen)
+        for i, expected := range test.nodes {
+            if nodes := perft(game.board, game.SideToMove(), i+1); nodes != expected {
+                t.Errorf("%s: perft(%d) = %d, expected %d", test.name, i+1, nodes, expected)
+            }
+        }
+    }
+}
+
+func TestLegalMoves(t *testing.T) {
+    tests := []struct {
+        name     string
+        fen      string
+        expected string //sorted legal moves
+    }{
+        {"pinned Rook moves along the pin", "4r2k/8/8/8/8/8/4R3/4K3 w - - 0 1",
+            "e1 d1 e1 d2 e1 f1 e1 f2 e2 e3 e2 e4 e2 e5 e2 e6 e2 e7 e2 e8"},
+        {"pinned Knight cannot move", "7k/8/8/b7/8/8/3N4/4K3 w - - 0 1",
+            "e1 d1 e1 e2 e1 f1 e1 f2"},
+        {"pinned Pawn cannot capture off the pin", "4r2k/8/8/8/8/3p4/4P3/4K3 w - - 0 1",
+            "e1 d1 e1 d2 e1 f1 e1 f2 e2 e3 e2 e4"},
+        {"double check, only the King moves", "4r2k/8/8/8/8/5n2/8/R3K3 w - - 0 1",
+            "e1 d1 e1 f1 e1 f2"},
+        {"King cannot step back along the checking ray", "7k/8/8/8/8/8/8/r3K3 w - - 0 1",
+            "e1 d2 e1 e2 e1 f2"},
+        {"single check is blocked", "4r2k/8/8/8/8/8/2B5/R3K3 w - - 0 1",
+            "c2 e4 e1 d1 e1 d2 e1 f1 e1 f2"},
+        {"Pawns only attack diagonally", "7k/8/8/8/8/3p4/8/4K3 w - - 0 1",
+            "e1 d1 e1 d2 e1 f1 e1 f2"},
+    }
+
+    for _, test := range tests {
+        game := newTestGame(t, test.fen)
+        moves := game.board.getLegalMoves(game.SideToMove())
+        sort.Strings(moves)
+        expected := []string{}
+        tokens := strings.Fields(test.expected)
+        for i := 0; i < len(tokens); i += 2 {
+            expected = append(expected, tokens[i]+" "+tokens[i+1])
+        }
+        sort.Strings(expected)
+        if strings.Join(moves, ", ") != strings.Join(expected, ", ") {
+            t.Errorf("%s: got %v, expected %v", test.name, moves, expected)
+        }
+    }
+}
+
+func TestIsSquareAttacked(t *testing.T) {
+    game := newTestGame(t, "4k3/8/8/8/8/3p4/8/R3K3 w - - 0 1")
+    tests := []struct {
+        position string
+        byTeam   Team
+        expected bool
+    }{
+        {"a8", white, true},
+        {"d4", white, false},
+        {"c2", black, true},
+        {"d2", black, false}, //Pawns only attack diagonally
+        {"d1", white, true},
+        {"h1", white, false}, //the King blocks the Rook
+        //Malformed squares are never attacked
+        {"", white, false},
+        {"e", white, false},
+        {"e22", white, false},
+        {"i1", white, false},
+        {"a9", white, false},
+        {"a0", white, false},
+    }
+
+    for _, test := range tests {
+        if attacked := game.board.IsSquareAttacked(test.position, test.byTeam); attacked != test.expected {
+            t.Errorf("%q attacked by %v: %v, expected %v", test.position, test.byTeam, attacked, test.expected)
+        }
+    }
+}
@@ -11,6 +11,10 @@ const boardSize = 8
 type Board struct {
     squares                      [][]Square
     whiteCaptures, blackCaptures []string
+    played                       []moveUndo     //moves made by execute(), most recent last
+    dropsAllowed                 bool           //crazyhouse: captures form pockets the pieces can be dropped from
+    castling                     castlingRights //Rooks that can still castle, see castling.go
+    chess960                     bool           //castlings are entered as the King moving onto its Rook
 }
 
 func NewBoard() *Board {
@@ -25,34 +29,57 @@ func NewBoard() *Board {
         }
     }
     board.squares = squares
+    board.castling = newCastlingRights()
     return board
 }
 
 func (board Board) String() string {
+    return board.render(utils.Renderer{}, nil)
+}
+
+// Stringify the board with the renderer, highlighting squares by their positions
+func (board Board) render(renderer utils.Renderer, highlights map[string]utils.Highlight) string {
     var buffer bytes.Buffer
 
-    boardStr := make([][]string, boardSize)
+    cells := make([][]utils.Cell, boardSize)
     for i := 0; i < boardSize; i++ {
-        boardStr[i] = make([]string, boardSize)
+        cells[i] = make([]utils.Cell, boardSize)
         for j := 0; j < boardSize; j++ {
-            boardStr[i][j] = board.squares[i][j].String()
+            square := board.squares[i][j]
+            cell := utils.Cell{Symbol: square.String(), Highlight: highlights[getSquarePosition(square)]}
+            if piece := square.getPiece(); piece != nil {
+                cell.Letter = getPieceLetter(piece.sign)
+                cell.White = piece.team == white
+            }
+            cells[i][j] = cell
         }
     }
-    buffer.WriteString(utils.StringifyBoard(boardStr))
+    buffer.WriteString(renderer.Render(cells))
 
-    //White Captures: [symbols...]
-    buffer.WriteString("White Captures: [")
+    label := "Captures"
+    if board.dropsAllowed {
+        label = "Pocket"
+    }
+
+    //White Captures: [symbols...], a pocket shows the pieces in White's color as they are dropped
+    buffer.WriteString("White " + label + ": [")
     for _, capturedSign := range board.whiteCaptures {
         if capturedSign != "" {
+            if board.dropsAllowed {
+                capturedSign = strings.ToLower(capturedSign)
+            }
             buffer.WriteString(getPieceSymbol(capturedSign) + " ") //cannot do getPieceSymbol("")
         }
     }
     buffer.WriteString("] \n")
 
-    //Black Captures: [symbols...]
-    buffer.WriteString("Black Captures: [")
+    //Black Captures: [symbols...], a pocket in Black's color
+    buffer.WriteString("Black " + label + ": [")
     for _, capturedSign := range board.blackCaptures {
         if capturedSign != "" {
+            if board.dropsAllowed {
+                capturedSign = strings.ToUpper(capturedSign)
+            }
             buffer.WriteString(getPieceSymbol(capturedSign) + " ") //cannot do getPieceSymbol("")
         }
     }
@@ -68,47 +95,50 @@ func (board *Board) setup(testCase utils.TestCase) {
 
     board.whiteCaptures = testCase.WhiteCaptures
     board.blackCaptures = testCase.BlackCaptures
+    board.setStandardCastling()
 }
 
 func (board *Board) initPiece(position string, sign string) {
     square := board.getSquare(position)
     if square == nil || square.hasPiece() {
         panic("initPiece() failed on the position: " + position)
-        return
     }
 
     piece := createPiece(sign, square.row, square.col)
     square.setPiece(&piece)
 }
 
-// Execute the command passed, return true if it's inCheckmate
-func (board *Board) execute(command string, team Team) bool {
-
-    //Handle "in check situation" first
-    if board.inCheck(team) {
-        validMoves := board.getAvailableMovesInCheck(team)
-        if !containsMove(validMoves, command) {
-            panic(illegalMoveMessage)
-        }
-    }
-
-    tokens := strings.Split(command, " ")
-    if len(tokens) != 2 {
-        panic(illegalMoveMessage)
-    }
+// Execute the command passed, return the move in SAN and true if it's inCheckmate
+func (board *Board) execute(command string, team Team) (string, bool) {
 
-    //Check the movePiece first, panic if it's illegal movePiece on board
-    move := board.checkMove(tokens[0], tokens[1], team)
+    //Check the movePiece (or drop) first, panic if it's illegal movePiece on board
+    move := board.parseCommand(command, team)
 
     //Move Piece
-    board.movePiece(move.piece, move.squareFrom, move.squareTo)
+    san := board.toSAN(move, team)
+    board.played = append(board.played, board.makeMove(move))
 
     //Promote
     //TODO: Handle promotion for Pawn
 
     //return if the opponent team is in checkmate
-    return board.inCheckmate(getOpponentTeam(team))
+    return board.addCheckSuffix(san, team), board.inCheckmate(getOpponentTeam(team))
+
+}
 
+// Get the move of the command ("e2 e4", a castling or a "N@f3" drop), panic if it's not legal
+func (board *Board) parseCommand(command string, team Team) Move {
+    if strings.Contains(command, dropSeparator) {
+        return board.checkDrop(command, team)
+    }
+    if castling, found := board.findCastling(command, team); found {
+        return castling
+    }
+    tokens := strings.Split(command, " ")
+    if len(tokens) != 2 {
+        panic(illegalMoveMessage)
+    }
+    return board.checkMove(tokens[0], tokens[1], team)
 }
 
 func (board *Board) movePiece(piece *Piece, squareFrom, squareTo *Square) {
@@ -140,107 +170,21 @@ func (board *Board) captured(capturedPiece Piece) {
 }
 
 func (board Board) inCheck(curTeam Team) bool {
-    kingPosition := board.getKingPosition(curTeam)
-    opponentPositions := board.getReachablePositions(getOpponentTeam(curTeam))
-    for _, position := range opponentPositions {
-        if kingPosition == position {
-            return true
-        }
-    }
-    return false
+    return board.IsSquareAttacked(board.getKingPosition(curTeam), getOpponentTeam(curTeam))
 }
 
-func (board Board) inCheckmate(curTeam Team) bool {
-    return board.inCheck(curTeam) && len(board.getAvailableMovesInCheck(curTeam)) == 0
+func (board *Board) inCheckmate(curTeam Team) bool {
+    return board.inCheck(curTeam) && len(board.getAvailableMoves(curTeam)) == 0
 }
 
-
-//Get all available moves to escape check for the given team
-func (board Board) getAvailableMovesInCheck(team Team) []string {
-    var availableMoves []string
-
-    //Escape check by moving King
-    availableMoves = append(availableMoves, board.getAvailableMovesByMovingKing(team)...)
-
-    //Escape check by moving other pieces than King
-    availableMoves = append(availableMoves, board.getAvailableMovesByMovingOtherPieces(team)...)
-
-    return availableMoves
+func (board *Board) inStalemate(curTeam Team) bool {
+    return !board.inCheck(curTeam) && len(board.getAvailableMoves(curTeam)) == 0
 }
 
-func (board Board) getAvailableMovesByMovingKing(team Team) []string {
-    //No need to check threatenKingPiece size cause we are moving the King to safe place directly
-    var moves []string
-    kingPosition := board.getKingPosition(team)
-    kingPiece := board.getSquare(kingPosition).piece
-
-    opponentMoves := board.getReachablePositions(getOpponentTeam(team))
-
-    for _, kingMove := range getMoves(board, *kingPiece) {
-        if !containsMove(opponentMoves, kingMove) {
-            moves = append(moves, kingPosition+" "+kingMove)
-        }
-    }
 
-    return moves
-}
-
-func (board Board) getAvailableMovesByMovingOtherPieces(team Team) []string {
-    var moves []string
-    threatenKingPieces := board.getThreateningKingPieces(team)
-    if len(threatenKingPieces) != 1 {
-        return moves //Too many threatening pieces to capture
-    }
-
-    threatenPiece := threatenKingPieces[0]
-    threatenPosition := getCoordinatePosition(threatenPiece.row, threatenPiece.col)
-
-    for _, ownPiece := range board.getAllPieces(team) {
-        if isKing(ownPiece) {
-            continue //king's move was already considered in method getAvailableMovesByMovingKing()
-        }
-        positionFrom := getCoordinatePosition(ownPiece.row, ownPiece.col)
-
-        for _, positionTo := range getMoves(board, ownPiece) {
-            if !board.moveWillCauseSelfCheck(positionFrom, positionTo, team) {
-                moves = append(moves, positionFrom + " " + threatenPosition)
-            }
-        }
-    }
-
-    return moves
-}
-
-
-func (board Board) getThreateningKingPieces(team Team) []Piece {
-    var threatenPieces []Piece
-    kingPosition := board.getKingPosition(team)
-    for _, opponentPiece := range board.getAllPieces(getOpponentTeam(team)) {
-        opponentMoves := getMoves(board, opponentPiece)
-        if containsMove(opponentMoves, kingPosition) {
-            threatenPieces = append(threatenPieces, opponentPiece)
-        }
-    }
-    return threatenPieces
-}
-
-func (board Board) moveWillCauseSelfCheck(positionFrom, positionTo string, team Team) bool {
-
-    squareFrom := board.getSquare(positionFrom)
-    squareTo := board.getSquare(positionTo)
-
-    pieceFrom := squareFrom.piece
-    pieceTo := squareTo.piece
-
-    //Move piece and check
-    board.movePiece(pieceFrom, squareFrom, squareTo)
-    selfInCheck := board.inCheck(team)
-
-    //Move pieces back
-    board.movePiece(pieceFrom, squareTo, squareFrom)
-    squareTo.setPiece(pieceTo)
-
-    return selfInCheck
+//Get all available moves of the given team, with the drops in crazyhouse
+func (board *Board) getAvailableMoves(team Team) []string {
+    return append(board.getLegalMoves(team), board.getLegalDrops(team)...)
 }
 
 func (board Board) canMoveTo(position string, team Team) bool {
@@ -253,6 +197,11 @@ func (board Board) canMoveTo(position string, team Team) bool {
     return piece == nil || piece.team != team
 }
 
+func (board Board) hasOpponentAt(position string, team Team) bool {
+    square := board.getSquare(position)
+    return square != nil && square.hasPiece() && square.getPiece().team != team
+}
+
 func (board Board) isEmptyAt(position string) bool {
     square := board.getSquare(position)
     return square != nil && !square.hasPiece()
@@ -279,13 +228,29 @@ func (board Board) getKingPosition(team Team) string {
     panic("Error: Cannot find King from the board")
 }
 
-func (board Board) getReachablePositions(team Team) []string {
-    var moves []string
+// Check if the team could still checkmate by any series of legal moves
+func (board Board) hasMatingMaterial(team Team) bool {
     pieces := board.getAllPieces(team)
-    for _, piece := range pieces {
-        moves = append(moves, getMoves(board, piece)...)
+    opponentPieces := board.getAllPieces(getOpponentTeam(team))
+
+    if len(board.getPocketKinds(white)) > 0 || len(board.getPocketKinds(black)) > 0 {
+        return true //pieces in a pocket can still be dropped
+    }
+
+    if len(pieces) == 1 {
+        return false //King only
+    }
+
+    //A single Bishop or Knight can only mate if the opponent has pieces to block its own King
+    if len(pieces) == 2 && len(opponentPieces) == 1 {
+        for _, piece := range pieces {
+            if piece.kind() == "b" || piece.kind() == "n" {
+                return false
+            }
+        }
     }
-    return moves
+
+    return true
 }
 
 func (board Board) getAllPieces(team Team) []Piece {
@@ -303,6 +268,9 @@ func (board Board) getAllPieces(team Team) []Piece {
 }
 
 func (board Board) getSquare(position string) *Square {
+    if len(position) != 2 {
+        return nil
+    }
     col := int(position[0] - 'a')
     if col < 0 || col >= boardSize {
         return nil
@@ -346,9 +314,11 @@ func (square Square) getPiece() *Piece {
 type Move struct {
     piece                *Piece
     squareFrom, squareTo *Square
+    rook                 *Piece  //Rook of a castling, nil for other moves
+    rookFrom, rookTo     *Square
 }
 
-func (board Board) checkMove(origin, destination string, team Team) Move {
+func (board *Board) checkMove(origin, destination string, team Team) Move {
 
     //Check input positions
     squareFrom := board.getSquare(origin)
@@ -365,18 +335,98 @@ func (board Board) checkMove(origin, destination string, team Team) Move {
     }
 
     //Check if the piece's movement is valid
-    moves := getMoves(board, *piece)
+    moves := getMoves(*board, *piece)
     if !containsMove(moves, destination) {
         panic(illegalMoveMessage)
     }
 
     //Check if causing self in check (considered as invalid movePiece in current rule)
-    if board.moveWillCauseSelfCheck(origin, destination, team) {
+    if !containsMove(board.getLegalMoves(team), origin+" "+destination) {
         panic(causingSelfInCheckMessage)
     }
 
-    return Move{piece, squareFrom, squareTo}
+    return Move{piece: piece, squareFrom: squareFrom, squareTo: squareTo}
+
+}
+
+// MARK: moveUndo (record of everything makeMove() changed, consumed by unmakeMove())
+type moveUndo struct {
+    move                                   Move
+    capturedPiece                          *Piece
+    whiteCapturesCount, blackCapturesCount int
+    pocket                                 []string //pocket of the dropping team before a drop, nil for moves
+    castling                               castlingRights
+}
+
+// Make the move on board and return the record to undo it
+func (board *Board) makeMove(move Move) moveUndo {
+    undo := moveUndo{move, move.squareTo.getPiece(), len(board.whiteCaptures), len(board.blackCaptures), nil, board.castling}
+    if move.squareFrom == nil {
+        undo.pocket = board.getPocket(move.piece.team)
+        board.drop(move.piece, move.squareTo)
+        return undo
+    }
+    board.updateCastlingRights(move)
+    if move.rook != nil {
+        undo.capturedPiece = nil //the King may go to its own Rook's square
+        board.castle(move)
+        return undo
+    }
+    board.movePiece(move.piece, move.squareFrom, move.squareTo)
+    return undo
+}
+
+// Restore the board to exactly the state before the makeMove() call that returned the undo record.
+// Moves must be unmade in the reverse order they were made.
+func (board *Board) unmakeMove(undo moveUndo) {
+    move := undo.move
+    board.castling = undo.castling
+
+    //Take dropped piece back to the pocket
+    if move.squareFrom == nil {
+        move.squareTo.setPiece(nil)
+        board.setPocket(move.piece.team, undo.pocket)
+        return
+    }
 
+    if move.rook != nil {
+        board.uncastle(move)
+        return
+    }
+
+    //Put moving piece back
+    move.squareFrom.setPiece(move.piece)
+    move.piece.row = move.squareFrom.row
+    move.piece.col = move.squareFrom.col
+
+    //Put captured piece (or nil) back
+    move.squareTo.setPiece(undo.capturedPiece)
+
+    //Drop the captures recorded by the move
+    board.whiteCaptures = board.whiteCaptures[:undo.whiteCapturesCount]
+    board.blackCaptures = board.blackCaptures[:undo.blackCapturesCount]
+}
+
+// Take back the last move made by execute(), return false if there is none
+func (board *Board) undoLastMove() bool {
+    if len(board.played) == 0 {
+        return false
+    }
+    board.unmakeMove(board.played[len(board.played)-1])
+    board.played = board.played[:len(board.played)-1]
+    return true
+}
+
+// Get the origin and destination of the last move made by execute() (only the destination of a drop), nil if there is none
+func (board Board) getLastMove() []string {
+    if len(board.played) == 0 {
+        return nil
+    }
+    move := board.played[len(board.played)-1].move
+    if move.squareFrom == nil {
+        return []string{getSquarePosition(*move.squareTo)}
+    }
+    return []string{getSquarePosition(*move.squareFrom), getSquarePosition(*move.squareTo)}
 }
 
 // MARK: Helper package functions
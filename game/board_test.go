@@ -0,0 +1,102 @@
+package game
+
+import (
+    "reflect"
+    "strings"
+    "testing"
+)
+
+// Everything makeMove() may change, to compare the board before and after a round trip
+type boardSnapshot struct {
+    placement                    string
+    whiteCaptures, blackCaptures []string
+    pieces                       []Piece
+    castling                     castlingRights
+}
+
+func takeSnapshot(board *Board) boardSnapshot {
+    snapshot := boardSnapshot{
+        placement:     board.FENPlacement(),
+        whiteCaptures: append([]string(nil), board.whiteCaptures...),
+        blackCaptures: append([]string(nil), board.blackCaptures...),
+        castling:      board.castling,
+    }
+    for i := 0; i < boardSize; i++ {
+        for j := 0; j < boardSize; j++ {
+            if piece := board.squares[i][j].getPiece(); piece != nil {
+                if piece.row != i || piece.col != j {
+                    return boardSnapshot{placement: "piece out of place at " + getCoordinatePosition(i, j)}
+                }
+                snapshot.pieces = append(snapshot.pieces, *piece)
+            }
+        }
+    }
+    return snapshot
+}
+
+func TestMakeUnmakeMoveRoundTrip(t *testing.T) {
+    fens := []string{
+        StartFEN,
+        "r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1", //many captures and both castlings
+        "8/2p5/3p4/KP5r/1R3p1k/8/4P1P1/8 w - - 0 1",
+        "4k3/8/8/8/8/8/8/R1RK4 w C - 0 1", //Chess960, the King and the Rook swap squares
+        "4k3/8/8/8/8/8/8/r3K3[QRBNPqrbnp] w - - 0 1", //Crazyhouse, drops must block the check
+        "rnbqkb1r/ppp1pppp/5n2/3P4/8/8/PPPP1PPP/RNBQKBNR[Pp] b - - 0 3",
+    }
+
+    for _, fen := range fens {
+        game, err := NewFromFEN(fen)
+        if err != nil {
+            t.Fatalf("%s: %v", fen, err)
+        }
+        board, team := game.board, game.SideToMove()
+        if strings.Contains(fen, "[") {
+            board.whiteCaptures = append(board.whiteCaptures, "R") //a capture recorded before the drops, must stay in order
+        }
+        before := takeSnapshot(board)
+
+        moves := board.getAvailableMoves(team)
+        if len(moves) == 0 {
+            t.Fatalf("%s: no moves", fen)
+        }
+        for _, command := range moves {
+            move := board.parseCommand(command, team)
+            undo := board.makeMove(move)
+            if board.FENPlacement() == before.placement {
+                t.Fatalf("%s: %s did not change the board", fen, command)
+            }
+            board.unmakeMove(undo)
+
+            if after := takeSnapshot(board); !reflect.DeepEqual(before, after) {
+                t.Fatalf("%s: %s not restored\nbefore %+v\nafter  %+v", fen, command, before, after)
+            }
+        }
+    }
+}
+
+func TestUndoLastMoveRestoresCaptures(t *testing.T) {
+    game := NewCrazyhouse()
+    moves := []string{"e2 e4", "d7 d5", "e4 d5", "d8 d5", "P@e4", "d5 e4"}
+    var snapshots []boardSnapshot
+    for _, move := range moves {
+        snapshots = append(snapshots, takeSnapshot(game.board))
+        if _, err := game.Move(move); err != nil {
+            t.Fatalf("%s: %v", move, err)
+        }
+    }
+    if fen := game.FEN(); !strings.Contains(fen, "[pp]") {
+        t.Fatalf("expected two Pawns in Black's pocket: %s", fen)
+    }
+
+    for i := len(moves) - 1; i >= 0; i-- {
+        if err := game.Undo(); err != nil {
+            t.Fatalf("undo %s: %v", moves[i], err)
+        }
+        if after := takeSnapshot(game.board); !reflect.DeepEqual(snapshots[i], after) {
+            t.Fatalf("undo %s: not restored\nbefore %+v\nafter  %+v", moves[i], snapshots[i], after)
+        }
+    }
+    if err := game.Undo(); err == nil {
+        t.Fatal("undo without moves should fail")
+    }
+}
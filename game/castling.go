@@ -0,0 +1,311 @@
+package game
+
+import (
+    "errors"
+    "strings"
+)
+
+// MARK: Castling (standard and Chess960)
+//
+// Castling moves the King and one of its Rooks in one move. Whatever their start squares, the King ends on the g-file
+// and the Rook on the f-file (O-O, with the Rook on the King's side), or the King on the c-file and the Rook on the
+// d-file (O-O-O, with the Rook on the Queen's side). It needs the right to castle with that Rook, lost once the King or
+// the Rook has moved or the Rook was captured. The squares both pieces cross or land on must be empty but for the
+// two of them, and the King must not be in check, cross an attacked square or end in check.
+//
+// A castling is entered like a King move to its square ("e1 g1"), in Chess960 as the King moving onto its Rook
+// ("g1 h1"), since the King might not move at all or only by one square.
+
+type castlingSide int
+const (
+    kingSide  castlingSide = iota
+    queenSide castlingSide = iota
+)
+
+// Files the King and the Rook end on, by castlingSide
+var (
+    castlingKingCols = [2]int{6, 2}
+    castlingRookCols = [2]int{5, 3}
+)
+
+const noCastling = -1
+
+// Files of the Rooks each team can still castle with, by team (White first) and castlingSide, noCastling when lost
+type castlingRights [2][2]int
+
+func newCastlingRights() castlingRights {
+    return castlingRights{{noCastling, noCastling}, {noCastling, noCastling}}
+}
+
+func (rights castlingRights) get(team Team, side castlingSide) int {
+    return rights[getTeamIndex(team)][side]
+}
+
+func (rights *castlingRights) set(team Team, side castlingSide, rookCol int) {
+    rights[getTeamIndex(team)][side] = rookCol
+}
+
+func getTeamIndex(team Team) int {
+    if team == black {
+        return 1
+    }
+    return 0
+}
+
+// Row of the team's back rank (row 0 is rank 8)
+func getBackRow(team Team) int {
+    if team == black {
+        return 0
+    }
+    return boardSize - 1
+}
+
+// Get the column of the team's King on its back rank, -1 if the King is elsewhere
+func (board Board) getBackRankKingCol(team Team) int {
+    row := getBackRow(team)
+    for col := 0; col < boardSize; col++ {
+        if board.hasPieceAt(row, col, team, "k") {
+            return col
+        }
+    }
+    return -1
+}
+
+// Give the rights of the standard setup to the Kings and Rooks that are on their start squares (playbook setups)
+func (board *Board) setStandardCastling() {
+    for _, team := range []Team{white, black} {
+        row := getBackRow(team)
+        if !board.hasPieceAt(row, 4, team, "k") {
+            continue
+        }
+        if board.hasPieceAt(row, boardSize-1, team, "r") {
+            board.castling.set(team, kingSide, boardSize-1)
+        }
+        if board.hasPieceAt(row, 0, team, "r") {
+            board.castling.set(team, queenSide, 0)
+        }
+    }
+}
+
+// Set the rights from the castling field of a FEN string: "-", standard ("KQkq"), Shredder-FEN ("HAha", the files of
+// the Rooks) or X-FEN (standard letters, plus file letters when the outermost Rook is not the one that castles).
+// Each right must have the King and the Rook on the back rank of its side. Rights that aren't those of the standard
+// setup make castling follow the Chess960 notation.
+func (board *Board) setCastlingField(field string) error {
+    board.castling = newCastlingRights()
+    if field == "-" {
+        return nil
+    }
+
+    for _, char := range field {
+        team, row := white, getBackRow(white)
+        if char >= 'a' && char <= 'z' {
+            team, row = black, getBackRow(black)
+        }
+        kingCol := board.getBackRankKingCol(team)
+        if kingCol < 0 {
+            return errors.New("castling right without a King on the back rank: " + string(char))
+        }
+
+        //Find the file of the Rook the right belongs to
+        rookCol := -1
+        switch lower := char | 0x20; {
+        case lower == 'k': //outermost Rook on the King side
+            for col := boardSize - 1; col > kingCol && rookCol < 0; col-- {
+                if board.hasPieceAt(row, col, team, "r") {
+                    rookCol = col
+                }
+            }
+        case lower == 'q': //outermost Rook on the Queen side
+            for col := 0; col < kingCol && rookCol < 0; col++ {
+                if board.hasPieceAt(row, col, team, "r") {
+                    rookCol = col
+                }
+            }
+        case lower >= 'a' && lower <= 'h':
+            if col := int(lower - 'a'); board.hasPieceAt(row, col, team, "r") {
+                rookCol = col
+            }
+            board.chess960 = true
+        default:
+            return errors.New("invalid castling right: " + string(char))
+        }
+        if rookCol < 0 {
+            return errors.New("castling right without its Rook: " + string(char))
+        }
+
+        side := kingSide
+        if rookCol < kingCol {
+            side = queenSide
+        }
+        if board.castling.get(team, side) != noCastling {
+            return errors.New("castling right appears twice: " + field)
+        }
+        board.castling.set(team, side, rookCol)
+        if kingCol != 4 || (rookCol != 0 && rookCol != boardSize-1) {
+            board.chess960 = true
+        }
+    }
+    return nil
+}
+
+// Get the castling field of the FEN string in X-FEN, which is the standard field ("KQkq") unless a Chess960 right
+// belongs to a Rook that isn't the outermost one on its side
+func (board Board) FENCastling() string {
+    field := ""
+    for _, team := range []Team{white, black} {
+        row := getBackRow(team)
+        for _, side := range []castlingSide{kingSide, queenSide} {
+            rookCol := board.castling.get(team, side)
+            if rookCol == noCastling {
+                continue
+            }
+
+            letter := "k"
+            if side == queenSide {
+                letter = "q"
+            }
+            //Another Rook further out on the same side would be the one the letter stands for
+            for col := rookCol + 1; side == kingSide && col < boardSize; col++ {
+                if board.hasPieceAt(row, col, team, "r") {
+                    letter = string(rune('a' + rookCol))
+                }
+            }
+            for col := rookCol - 1; side == queenSide && col >= 0; col-- {
+                if board.hasPieceAt(row, col, team, "r") {
+                    letter = string(rune('a' + rookCol))
+                }
+            }
+
+            if team == white {
+                letter = strings.ToUpper(letter)
+            }
+            field += letter
+        }
+    }
+    if field == "" {
+        return "-"
+    }
+    return field
+}
+
+// Get the castlings the team can make now
+func (board Board) getCastlingMoves(team Team) []Move {
+    var moves []Move
+    row := getBackRow(team)
+    opponent := getOpponentTeam(team)
+
+    for _, side := range []castlingSide{kingSide, queenSide} {
+        rookCol := board.castling.get(team, side)
+        kingCol := board.getBackRankKingCol(team)
+        if rookCol == noCastling || kingCol < 0 || !board.hasPieceAt(row, rookCol, team, "r") {
+            continue
+        }
+        kingFrom, rookFrom := &board.squares[row][kingCol], &board.squares[row][rookCol]
+        kingTo, rookTo := castlingKingCols[side], castlingRookCols[side]
+
+        if !board.isRankClear(row, kingCol, kingTo, kingFrom, rookFrom) || !board.isRankClear(row, rookCol, rookTo, kingFrom, rookFrom) {
+            continue
+        }
+
+        //The Rook is lifted too, it may shield the King's new square from a Rook or Queen on the back rank
+        attacked := false
+        for col := kingCol; !attacked; col += sign(kingTo - col) {
+            attacked = board.isAttackedAt(row, col, opponent, kingFrom, rookFrom)
+            if col == kingTo {
+                break
+            }
+        }
+        if attacked {
+            continue
+        }
+
+        moves = append(moves, Move{piece: kingFrom.getPiece(), squareFrom: kingFrom, squareTo: &board.squares[row][kingTo],
+            rook: rookFrom.getPiece(), rookFrom: rookFrom, rookTo: &board.squares[row][rookTo]})
+    }
+    return moves
+}
+
+// Check that the squares of the row between the columns (both included) are empty, apart from the King's and the Rook's
+func (board Board) isRankClear(row, fromCol, toCol int, kingSquare, rookSquare *Square) bool {
+    for col := fromCol; ; col += sign(toCol - col) {
+        square := &board.squares[row][col]
+        if square.hasPiece() && square != kingSquare && square != rookSquare {
+            return false
+        }
+        if col == toCol {
+            return true
+        }
+    }
+}
+
+// Get the command of the castling as it's entered and listed with the legal moves
+func (board Board) getCastlingCommand(move Move) string {
+    squareTo := move.squareTo
+    if board.chess960 {
+        squareTo = move.rookFrom
+    }
+    return getSquarePosition(*move.squareFrom) + " " + getSquarePosition(*squareTo)
+}
+
+// Get the castling the command stands for, false if it isn't one the team can make
+func (board Board) findCastling(command string, team Team) (Move, bool) {
+    for _, move := range board.getCastlingMoves(team) {
+        if board.getCastlingCommand(move) == command {
+            return move, true
+        }
+    }
+    return Move{}, false
+}
+
+// Move the King and the Rook of the castling, both are lifted first as they may land on each other's square
+func (board *Board) castle(move Move) {
+    move.squareFrom.setPiece(nil)
+    move.rookFrom.setPiece(nil)
+    board.placePiece(move.piece, move.squareTo)
+    board.placePiece(move.rook, move.rookTo)
+}
+
+// Take the castling back, the reverse of castle()
+func (board *Board) uncastle(move Move) {
+    move.squareTo.setPiece(nil)
+    move.rookTo.setPiece(nil)
+    board.placePiece(move.piece, move.squareFrom)
+    board.placePiece(move.rook, move.rookFrom)
+}
+
+func (board *Board) placePiece(piece *Piece, square *Square) {
+    square.setPiece(piece)
+    piece.row = square.row
+    piece.col = square.col
+}
+
+// A King that moves loses both rights of its team, a Rook loses its right when it moves or is captured
+func (board *Board) updateCastlingRights(move Move) {
+    if isKing(*move.piece) {
+        board.castling.set(move.piece.team, kingSide, noCastling)
+        board.castling.set(move.piece.team, queenSide, noCastling)
+    }
+    for _, team := range []Team{white, black} {
+        row := getBackRow(team)
+        for _, side := range []castlingSide{kingSide, queenSide} {
+            rookCol := board.castling.get(team, side)
+            if rookCol == noCastling {
+                continue
+            }
+            for _, square := range []*Square{move.squareFrom, move.squareTo} {
+                if square.row == row && square.col == rookCol {
+                    board.castling.set(team, side, noCastling)
+                }
+            }
+        }
+    }
+}
+
+// SAN of the castling, "O-O" with the Rook on the King's side and "O-O-O" on the Queen's side
+func getCastlingSAN(move Move) string {
+    if move.rookFrom.col > move.squareFrom.col {
+        return "O-O"
+    }
+    return "O-O-O"
+}
@@ -0,0 +1,141 @@
+package game
+
+import (
+    "sort"
+    "strings"
+    "testing"
+)
+
+func TestCastlingMoves(t *testing.T) {
+    tests := []struct {
+        name      string
+        fen       string
+        castlings string //castling commands among the legal moves, sorted
+    }{
+        {"both sides", "r3k2r/8/8/8/8/8/8/R3K2R w KQkq - 0 1", "e1 c1,e1 g1"},
+        {"Black", "r3k2r/8/8/8/8/8/8/R3K2R b KQkq - 0 1", "e8 c8,e8 g8"},
+        {"no rights", "r3k2r/8/8/8/8/8/8/R3K2R w kq - 0 1", ""},
+        {"only the Queen's side", "r3k2r/8/8/8/8/8/8/R3K2R w Q - 0 1", "e1 c1"},
+        {"piece in between", "4k3/8/8/8/8/8/8/RN2K1NR w KQ - 0 1", ""},
+        {"free path", "4k3/8/8/8/8/8/8/R3K2R w KQ - 0 1", "e1 c1,e1 g1"},
+        {"in check by a Rook", "4r1k1/8/8/8/8/8/8/R3K2R w KQ - 0 1", ""},
+        {"crossing an attacked square", "5rk1/8/8/8/8/8/8/R3K2R w KQ - 0 1", "e1 c1"},
+        {"landing on an attacked square", "2k3r1/8/8/8/8/8/8/R3K2R w KQ - 0 1", "e1 c1"},
+        {"only the Rook crosses an attacked square", "1r4k1/8/8/8/8/8/8/R3K2R w KQ - 0 1", "e1 c1,e1 g1"},
+        {"Chess960 King doesn't move", "4k3/8/8/8/8/8/8/6KR w H - 0 1", "g1 h1"},
+        {"Chess960 Rook next to the King on the Queen's side", "4k3/8/8/8/8/8/8/5RK1 w F - 0 1", "g1 f1"},
+        {"Chess960 Rook doesn't move", "4k3/8/8/8/8/8/8/2K2R2 w F - 0 1", "c1 f1"},
+        {"Chess960 neighbours", "4k3/8/8/8/8/8/8/1RK5 w B - 0 1", "c1 b1"},
+        {"Chess960 Rook shields the King's square", "4k3/8/8/8/8/8/8/qRK5 w B - 0 1", ""},
+        {"Chess960 X-FEN inner Rook", "4k3/8/8/8/8/8/8/R1RK4 w C - 0 1", "d1 c1"},
+    }
+
+    for _, test := range tests {
+        game := newTestGame(t, test.fen)
+        var castlings []string
+        for _, move := range game.board.getCastlingMoves(game.SideToMove()) {
+            castlings = append(castlings, game.board.getCastlingCommand(move))
+            if !containsMove(game.LegalMoves(), castlings[len(castlings)-1]) {
+                t.Errorf("%s: %s missing from the legal moves", test.name, castlings[len(castlings)-1])
+            }
+        }
+        sort.Strings(castlings)
+        if got := strings.Join(castlings, ","); got != test.castlings {
+            t.Errorf("%s: castlings %q, expected %q", test.name, got, test.castlings)
+        }
+    }
+}
+
+func TestCastlingPlay(t *testing.T) {
+    tests := []struct {
+        name  string
+        fen   string
+        moves []string
+        san   []string
+        fen2  string //FEN after the moves
+    }{
+        {"O-O and O-O-O", "r3k2r/8/8/8/8/8/8/R3K2R w KQkq - 0 1", []string{"e1 g1", "e8 c8"}, []string{"O-O", "O-O-O"},
+            "2kr3r/8/8/8/8/8/8/R4RK1 w - - 2 2"},
+        {"King move loses both rights", "r3k2r/8/8/8/8/8/8/R3K2R w KQkq - 0 1", []string{"e1 e2", "e8 e7", "e2 e1"}, []string{"Ke2", "Ke7", "Ke1"},
+            "r6r/4k3/8/8/8/8/8/R3K2R b - - 3 2"},
+        {"Rook move and capture lose one right", "r3k2r/8/8/8/8/8/8/R3K2R w KQkq - 0 1", []string{"a1 a8"}, []string{"Rxa8+"},
+            "R3k2r/8/8/8/8/8/8/4K2R b Kk - 0 1"},
+        {"Chess960 O-O with the King onto its Rook", "4k3/8/8/8/8/8/8/1R4KR w KQ - 0 1", []string{"g1 h1"}, []string{"O-O"},
+            "4k3/8/8/8/8/8/8/1R3RK1 b - - 1 1"},
+    }
+
+    for _, test := range tests {
+        game := newTestGame(t, test.fen)
+        for i, move := range test.moves {
+            san, err := game.Move(move)
+            if err != nil {
+                t.Fatalf("%s: %s %v", test.name, move, err)
+            }
+            if san != test.san[i] {
+                t.Errorf("%s: %s in SAN is %s, expected %s", test.name, move, san, test.san[i])
+            }
+        }
+        if fen := game.FEN(); fen != test.fen2 {
+            t.Errorf("%s: FEN %s, expected %s", test.name, fen, test.fen2)
+        }
+
+        //Taking every move back restores the rights too
+        for range test.moves {
+            if err := game.Undo(); err != nil {
+                t.Fatal(err)
+            }
+        }
+        if fen := game.FEN(); fen != test.fen {
+            t.Errorf("%s: FEN after the take-backs %s, expected %s", test.name, fen, test.fen)
+        }
+    }
+}
+
+func TestCastlingFENField(t *testing.T) {
+    tests := []struct {
+        fen      string
+        field    string //castling field written back, "" if the FEN is invalid
+        chess960 bool
+    }{
+        {StartFEN, "KQkq", false},
+        {"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w HAha - 0 1", "KQkq", true}, //Shredder-FEN
+        {"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w - - 0 1", "-", false},
+        {"bbqnnrkr/pppppppp/8/8/8/8/PPPPPPPP/BBQNNRKR w HFhf - 0 1", "KQkq", true},
+        {"4k3/8/8/8/8/8/8/R1RK4 w C - 0 1", "C", true},       //X-FEN, the a1 Rook would be Q
+        {"4k3/8/8/8/8/8/8/R1RK4 w A - 0 1", "Q", true},       //outermost Rook
+        {"4k3/8/8/8/8/8/8/R3K2R w KQK - 0 1", "", false},     //twice
+        {"4k3/8/8/8/8/8/8/R3K2R w KH - 0 1", "", false},      //same Rook twice
+        {"4k3/8/8/8/8/8/8/R3K3 w K - 0 1", "", false},        //no Rook on the King's side
+        {"4k3/8/8/8/8/8/4K3/R6R w KQ - 0 1", "", false},      //King not on the back rank
+        {"4k3/8/8/8/8/8/8/R3K2R w X - 0 1", "", false},
+    }
+
+    for _, test := range tests {
+        game, err := NewFromFEN(test.fen)
+        if test.field == "" {
+            if err == nil {
+                t.Errorf("%s: expected an error", test.fen)
+            }
+            continue
+        }
+        if err != nil {
+            t.Errorf("%s: %v", test.fen, err)
+            continue
+        }
+        if field := strings.Fields(game.FEN())[2]; field != test.field || game.board.chess960 != test.chess960 {
+            t.Errorf("%s: field %s Chess960 %v, expected %s %v", test.fen, field, game.board.chess960, test.field, test.chess960)
+        }
+    }
+}
+
+func TestChess960StartPositionsCastle(t *testing.T) {
+    for index := 0; index < Chess960Positions; index++ {
+        game, err := NewChess960(index)
+        if err != nil {
+            t.Fatalf("position %d: %v", index, err)
+        }
+        if field := strings.Fields(game.FEN())[2]; field != "KQkq" {
+            t.Fatalf("position %d: castling field %s", index, field)
+        }
+    }
+}
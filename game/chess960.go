@@ -0,0 +1,79 @@
+package game
+
+import (
+    "errors"
+    "strconv"
+    "strings"
+)
+
+const (
+    Chess960Positions        = 960
+    Chess960StandardPosition = 518 //index of the standard start position
+    chess960Variant          = "Chess960"
+)
+
+// Squares of the two Knights among the five squares left after placing the Bishops and the Queen
+var chess960Knights = [10][2]int{{0, 1}, {0, 2}, {0, 3}, {0, 4}, {1, 2}, {1, 3}, {1, 4}, {2, 3}, {2, 4}, {3, 4}}
+
+// Get White's back rank of the Chess960 start position with the index (0-959, Scharnagl numbering), e.g. "RNBQKBNR" for 518
+func Chess960BackRank(index int) (string, error) {
+    if index < 0 || index >= Chess960Positions {
+        return "", errors.New("Chess960 position must be between 0 and 959: " + strconv.Itoa(index))
+    }
+
+    rank := make([]byte, boardSize)
+    rank[(index%4)*2+1] = 'B' //light squared Bishop on b, d, f or h
+    index /= 4
+    rank[(index%4)*2] = 'B' //dark squared Bishop on a, c, e or g
+    index /= 4
+
+    //Queen, Knights, then Rook, King, Rook on the empty squares from left to right
+    placeOnEmpty(rank, index%6, 'Q')
+    knights := chess960Knights[index/6]
+    placeOnEmpty(rank, knights[1], 'N') //place the right one first so the left one's count is unchanged
+    placeOnEmpty(rank, knights[0], 'N')
+    for _, piece := range []byte{'R', 'K', 'R'} {
+        placeOnEmpty(rank, 0, piece)
+    }
+    return string(rank), nil
+}
+
+// Put the piece on the nth (from 0) empty square of the rank
+func placeOnEmpty(rank []byte, n int, piece byte) {
+    for i := range rank {
+        if rank[i] != 0 {
+            continue
+        }
+        if n == 0 {
+            rank[i] = piece
+            return
+        }
+        n--
+    }
+}
+
+// Get the FEN of the Chess960 start position with the index. The castling rights are "KQkq" as in X-FEN,
+// each side has one Rook on either side of its King.
+func Chess960FEN(index int) (string, error) {
+    backRank, err := Chess960BackRank(index)
+    if err != nil {
+        return "", err
+    }
+    return strings.ToLower(backRank) + "/pppppppp/8/8/8/8/PPPPPPPP/" + backRank + " w KQkq - 0 1", nil
+}
+
+// Create a Chess960 game starting from the position with the index
+func NewChess960(index int) (ChessGame, error) {
+    fen, err := Chess960FEN(index)
+    if err != nil {
+        return ChessGame{}, err
+    }
+    game, err := NewFromFEN(fen)
+    if err != nil {
+        return ChessGame{}, err
+    }
+    game.variant = chess960Variant
+    game.board.chess960 = true //also for the standard position, castlings are entered the same way in every position
+    game.startFEN = fen //also for the standard position, a Chess960 game always has its start in PGN
+    return game, nil
+}
@@ -0,0 +1,137 @@
+package game
+
+import (
+    "fmt"
+    "time"
+)
+
+// MARK: TimeControl (same settings are used for both players)
+type TimeControl struct {
+    Base            time.Duration //time on the clock at the start of each session
+    Increment       time.Duration //Fischer increment, added after every move
+    Delay           time.Duration //Bronstein delay, the time used for a move is given back up to this amount
+    MovesPerSession int           //number of moves after which Base is added again, 0 for sudden death
+}
+
+func (control TimeControl) String() string {
+    str := formatDuration(control.Base)
+    if control.MovesPerSession > 0 {
+        str = fmt.Sprintf("%d moves in %s", control.MovesPerSession, str)
+    }
+    if control.Increment > 0 {
+        str += " +" + control.Increment.String() + " increment"
+    }
+    if control.Delay > 0 {
+        str += " +" + control.Delay.String() + " delay"
+    }
+    return str
+}
+
+// MARK: Clock (one per player)
+type Clock struct {
+    control   TimeControl
+    remaining time.Duration
+    movesMade int
+    running   bool
+    turnStart time.Time
+    credits   []time.Duration //time added after each move (delay, increment and session time), most recent last
+}
+
+func NewClock(control TimeControl) *Clock {
+    return &Clock{control: control, remaining: control.Base}
+}
+
+// Start counting down, does nothing if the clock is already running
+func (clock *Clock) Start(now time.Time) {
+    if clock.running {
+        return
+    }
+    clock.running = true
+    clock.turnStart = now
+}
+
+// Stop the clock after a move and apply delay, increment and session time.
+// Return true if the flag fell before the move was made.
+func (clock *Clock) Stop(now time.Time) bool {
+    if !clock.running {
+        return clock.remaining <= 0
+    }
+    clock.running = false
+
+    used := now.Sub(clock.turnStart)
+    clock.remaining -= used
+    if clock.remaining <= 0 {
+        clock.remaining = 0
+        return true
+    }
+
+    //Bronstein delay never gives back more than the time used
+    credit := clock.control.Delay
+    if used < clock.control.Delay {
+        credit = used
+    }
+    credit += clock.control.Increment
+
+    clock.movesMade++
+    if clock.control.MovesPerSession > 0 && clock.movesMade%clock.control.MovesPerSession == 0 {
+        credit += clock.control.Base
+    }
+    clock.remaining += credit
+    clock.credits = append(clock.credits, credit)
+    return false
+}
+
+// Remove the time added after the last move when it's taken back, the time the move used is not given back
+func (clock *Clock) takeBack() {
+    if len(clock.credits) == 0 {
+        return
+    }
+    last := len(clock.credits) - 1
+    clock.remaining -= clock.credits[last]
+    clock.credits = clock.credits[:last]
+    clock.movesMade--
+    if clock.remaining < 0 {
+        clock.remaining = 0
+    }
+}
+
+// Stop the clock without finishing a move (e.g. the move is taken back), no delay or increment is applied
+func (clock *Clock) pause(now time.Time) {
+    if !clock.running {
+        return
+    }
+    clock.running = false
+    clock.remaining -= now.Sub(clock.turnStart)
+    if clock.remaining < 0 {
+        clock.remaining = 0
+    }
+}
+
+// Get the time left at the given moment, counting the running turn
+func (clock *Clock) Remaining(now time.Time) time.Duration {
+    remaining := clock.remaining
+    if clock.running {
+        remaining -= now.Sub(clock.turnStart)
+    }
+    if remaining < 0 {
+        return 0
+    }
+    return remaining
+}
+
+func (clock *Clock) String() string {
+    return formatDuration(clock.Remaining(time.Now()))
+}
+
+// Format as "m:ss", showing tenths of seconds when time is running low
+func formatDuration(duration time.Duration) string {
+    if duration < 0 {
+        duration = 0
+    }
+    minutes := int(duration / time.Minute)
+    seconds := duration % time.Minute
+    if duration < 20*time.Second {
+        return fmt.Sprintf("%d:%04.1f", minutes, seconds.Seconds())
+    }
+    return fmt.Sprintf("%d:%02d", minutes, int(seconds/time.Second))
+}
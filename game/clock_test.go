@@ -0,0 +1,144 @@
+package game
+
+import (
+    "testing"
+    "time"
+)
+
+// Fixed start so the clocks are driven by the durations of the test, never by the wall clock
+var clockTestStart = time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+// Play moves that take the given times on the clock, return the time left and whether the flag fell
+func playClockMoves(clock *Clock, moveTimes []time.Duration) (time.Duration, bool) {
+    now := clockTestStart
+    for _, used := range moveTimes {
+        clock.Start(now)
+        now = now.Add(used)
+        if clock.Stop(now) {
+            return clock.Remaining(now), true
+        }
+    }
+    return clock.Remaining(now), false
+}
+
+func TestClock(t *testing.T) {
+    tests := []struct {
+        name      string
+        control   TimeControl
+        moveTimes []time.Duration
+        remaining time.Duration
+        flagged   bool
+    }{
+        {"sudden death", TimeControl{Base: time.Minute},
+            []time.Duration{10 * time.Second, 5 * time.Second}, 45 * time.Second, false},
+        {"Fischer increment after every move", TimeControl{Base: time.Minute, Increment: 2 * time.Second},
+            []time.Duration{10 * time.Second, time.Second}, 53 * time.Second, false},
+        {"increment on an instant move", TimeControl{Base: time.Minute, Increment: 2 * time.Second},
+            []time.Duration{0}, 62 * time.Second, false},
+        {"Bronstein delay gives back the time used", TimeControl{Base: time.Minute, Delay: 5 * time.Second},
+            []time.Duration{3 * time.Second, 5 * time.Second}, time.Minute, false},
+        {"Bronstein delay never gives back more than the time used", TimeControl{Base: time.Minute, Delay: 5 * time.Second},
+            []time.Duration{time.Second}, time.Minute, false},
+        {"Bronstein delay on a long move", TimeControl{Base: time.Minute, Delay: 5 * time.Second},
+            []time.Duration{12 * time.Second}, 53 * time.Second, false},
+        {"delay and increment", TimeControl{Base: time.Minute, Increment: time.Second, Delay: 2 * time.Second},
+            []time.Duration{10 * time.Second}, 53 * time.Second, false},
+        {"session time after the last move of the session", TimeControl{Base: time.Minute, MovesPerSession: 2},
+            []time.Duration{10 * time.Second, 10 * time.Second}, 100 * time.Second, false},
+        {"session time once per session", TimeControl{Base: time.Minute, MovesPerSession: 2},
+            []time.Duration{10 * time.Second, 10 * time.Second, 10 * time.Second}, 90 * time.Second, false},
+        {"flag falls", TimeControl{Base: time.Minute, Increment: 10 * time.Second},
+            []time.Duration{30 * time.Second, 45 * time.Second}, 0, true},
+        {"no increment once the flag fell", TimeControl{Base: time.Minute, Increment: 10 * time.Second},
+            []time.Duration{time.Minute}, 0, true},
+    }
+
+    for _, test := range tests {
+        remaining, flagged := playClockMoves(NewClock(test.control), test.moveTimes)
+        if remaining != test.remaining || flagged != test.flagged {
+            t.Errorf("%s: %v left, flagged %v, expected %v left, flagged %v", test.name, remaining, flagged, test.remaining, test.flagged)
+        }
+    }
+}
+
+func TestClockRunningTurn(t *testing.T) {
+    clock := NewClock(TimeControl{Base: time.Minute, Increment: 2 * time.Second})
+    clock.Start(clockTestStart)
+    clock.Start(clockTestStart.Add(5 * time.Second)) //already running, the turn still started first
+
+    if remaining := clock.Remaining(clockTestStart.Add(20 * time.Second)); remaining != 40*time.Second {
+        t.Errorf("time left during the turn: %v", remaining)
+    }
+    if remaining := clock.Remaining(clockTestStart.Add(2 * time.Minute)); remaining != 0 {
+        t.Errorf("time left after the flag fell: %v", remaining)
+    }
+
+    //A pause applies no increment
+    clock.pause(clockTestStart.Add(20 * time.Second))
+    if remaining := clock.Remaining(clockTestStart.Add(time.Hour)); remaining != 40*time.Second {
+        t.Errorf("time left after the pause: %v", remaining)
+    }
+
+    //Stopping a clock that isn't running only reports the flag
+    if clock.Stop(clockTestStart.Add(time.Hour)) || clock.movesMade != 0 {
+        t.Error("stopping a paused clock must not count a move")
+    }
+}
+
+func TestClockTakeBack(t *testing.T) {
+    clock := NewClock(TimeControl{Base: time.Minute, Delay: 5 * time.Second, MovesPerSession: 2})
+    playClockMoves(clock, []time.Duration{10 * time.Second, 3 * time.Second}) //credits 5s, then 3s and the session
+
+    clock.takeBack()
+    if clock.remaining != 52*time.Second || clock.movesMade != 1 {
+        t.Errorf("after taking back the session move: %v left, %d moves", clock.remaining, clock.movesMade)
+    }
+    clock.takeBack()
+    if clock.remaining != 47*time.Second || clock.movesMade != 0 {
+        t.Errorf("after taking back both moves: %v left, %d moves", clock.remaining, clock.movesMade)
+    }
+    clock.takeBack() //nothing left to take back
+    if clock.remaining != 47*time.Second {
+        t.Errorf("taking back without moves changed the clock: %v", clock.remaining)
+    }
+}
+
+func TestFormatDuration(t *testing.T) {
+    tests := []struct {
+        duration time.Duration
+        expected string
+    }{
+        {5 * time.Minute, "5:00"},
+        {90*time.Minute + 5*time.Second, "90:05"},
+        {time.Minute + 59*time.Second + 900*time.Millisecond, "1:59"}, //whole seconds are cut, not rounded
+        {20 * time.Second, "0:20"},
+        {19*time.Second + 960*time.Millisecond, "0:20.0"},
+        {19*time.Second + 940*time.Millisecond, "0:19.9"},
+        {3*time.Second + 250*time.Millisecond, "0:03.2"},
+        {0, "0:00.0"},
+        {-time.Second, "0:00.0"},
+    }
+
+    for _, test := range tests {
+        if formatted := formatDuration(test.duration); formatted != test.expected {
+            t.Errorf("%v: %s, expected %s", test.duration, formatted, test.expected)
+        }
+    }
+}
+
+func TestTimeControlString(t *testing.T) {
+    tests := []struct {
+        control  TimeControl
+        expected string
+    }{
+        {TimeControl{Base: 5 * time.Minute}, "5:00"},
+        {TimeControl{Base: 3 * time.Minute, Increment: 2 * time.Second}, "3:00 +2s increment"},
+        {TimeControl{Base: 90 * time.Minute, MovesPerSession: 40, Delay: 5 * time.Second}, "40 moves in 90:00 +5s delay"},
+    }
+
+    for _, test := range tests {
+        if str := test.control.String(); str != test.expected {
+            t.Errorf("%+v: %s, expected %s", test.control, str, test.expected)
+        }
+    }
+}
@@ -0,0 +1,141 @@
+package game
+
+import (
+    "strings"
+)
+
+// MARK: Crazyhouse (captured pieces join the capturer's pocket and can be dropped back on the board)
+//
+// A drop is entered as "N@f3": the piece letter, "@" and the square. Pawns cannot be dropped on the first or last rank,
+// and like any move a drop must not leave the own King in check, so dropping between the King and a checker is allowed.
+
+const (
+    crazyhouseVariant = "Crazyhouse"
+    dropSeparator     = "@"
+    pocketKinds       = "pnbrq" //kinds a pocket can hold, in the order they are listed
+)
+
+// Start position of Crazyhouse, the pockets in brackets after the piece placement (empty at the start)
+const CrazyhouseStartFEN = "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR[] w KQkq - 0 1"
+
+func NewCrazyhouse() ChessGame {
+    game, err := NewFromFEN(CrazyhouseStartFEN)
+    if err != nil {
+        panic("invalid Crazyhouse start position: " + err.Error())
+    }
+    return game
+}
+
+// Get the pocket of the team, the signs of the pieces it captured (they change sides when dropped)
+func (board Board) getPocket(team Team) []string {
+    if team == black {
+        return board.blackCaptures
+    }
+    return board.whiteCaptures
+}
+
+func (board *Board) setPocket(team Team, pocket []string) {
+    if team == black {
+        board.blackCaptures = pocket
+    } else {
+        board.whiteCaptures = pocket
+    }
+}
+
+// Get the kinds ("p", "n", ...) of the pieces the team can drop, none when drops are not allowed
+func (board Board) getPocketKinds(team Team) []string {
+    if !board.dropsAllowed {
+        return nil
+    }
+    var kinds []string
+    for _, kind := range strings.Split(pocketKinds, "") {
+        for _, sign := range board.getPocket(team) {
+            if strings.ToLower(sign) == kind {
+                kinds = append(kinds, kind)
+                break
+            }
+        }
+    }
+    return kinds
+}
+
+// Get all legal drops ("N@f3") of the team
+func (board Board) getLegalDrops(team Team) []string {
+    kinds := board.getPocketKinds(team)
+    if len(kinds) == 0 {
+        return nil
+    }
+
+    //A drop cannot expose the King, it only has to block a single check
+    checkers := board.getCheckers(team)
+    if len(checkers) > 1 {
+        return nil //Double check, only the King can move
+    }
+    var resolvingSquares map[string]bool
+    if len(checkers) == 1 {
+        resolvingSquares = board.getCheckResolvingSquares(board.getSquare(board.getKingPosition(team)), checkers[0])
+    }
+
+    var drops []string
+    for i := 0; i < boardSize; i++ {
+        for j := 0; j < boardSize; j++ {
+            position := getCoordinatePosition(i, j)
+            if board.squares[i][j].hasPiece() || (resolvingSquares != nil && !resolvingSquares[position]) {
+                continue
+            }
+            for _, kind := range kinds {
+                if kind == "p" && (i == 0 || i == boardSize-1) {
+                    continue //no Pawns on the first or last rank
+                }
+                drops = append(drops, strings.ToUpper(kind)+dropSeparator+position)
+            }
+        }
+    }
+    return drops
+}
+
+// Check the drop command ("N@f3", the letter in either case) and return it as a Move without origin
+func (board *Board) checkDrop(command string, team Team) Move {
+    if !board.dropsAllowed || len(command) != 4 || command[1:2] != dropSeparator {
+        panic(illegalMoveMessage)
+    }
+
+    kind := strings.ToLower(command[:1])
+    squareTo := board.getSquare(command[2:])
+    if squareTo == nil || squareTo.hasPiece() || !containsMove(board.getPocketKinds(team), kind) {
+        panic(illegalMoveMessage)
+    }
+    if kind == "p" && (squareTo.row == 0 || squareTo.row == boardSize-1) {
+        panic(illegalMoveMessage)
+    }
+    if !containsMove(board.getLegalDrops(team), strings.ToUpper(kind)+dropSeparator+command[2:]) {
+        panic(causingSelfInCheckMessage)
+    }
+
+    sign := kind
+    if team == black {
+        sign = strings.ToUpper(kind)
+    }
+    piece := createPiece(sign, squareTo.row, squareTo.col)
+    return Move{piece: &piece, squareTo: squareTo}
+}
+
+// Take the piece out of its team's pocket and put it on the square
+func (board *Board) drop(piece *Piece, squareTo *Square) {
+    pocket := board.getPocket(piece.team)
+    for i, sign := range pocket {
+        if strings.ToLower(sign) == piece.kind() {
+            //Copy so that the pocket kept for undoing the drop is not changed
+            board.setPocket(piece.team, append(append([]string(nil), pocket[:i]...), pocket[i+1:]...))
+            break
+        }
+    }
+    squareTo.setPiece(piece)
+}
+
+// Get the pockets in FEN, e.g. "[Nnp]": White's pieces in upper case, Black's in lower case
+func (board Board) FENPocket() string {
+    //Pockets hold the signs of the captured pieces, the board's case being the opposite of FEN
+    //makes them the FEN letters of the pieces the capturer drops
+    return "[" + strings.Join(board.whiteCaptures, "") + strings.Join(board.blackCaptures, "") + "]"
+}
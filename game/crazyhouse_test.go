@@ -0,0 +1,50 @@
+package game
+
+import (
+    "strings"
+    "testing"
+)
+
+func playTestMoves(t *testing.T, game *ChessGame, moves ...string) {
+    for _, move := range moves {
+        if _, err := game.Move(move); err != nil {
+            t.Fatalf("%s: %v", move, err)
+        }
+    }
+}
+
+func TestPocketShowsOwnersColor(t *testing.T) {
+    crazyhouse, standard := NewCrazyhouse(), newTestGame(t, StartFEN)
+    for _, game := range []*ChessGame{&crazyhouse, &standard} {
+        playTestMoves(t, game, "e2 e4", "d7 d5", "e4 d5", "d8 d5")
+    }
+
+    //White's pocket holds a Pawn White can drop, Black's a Pawn Black can drop
+    board := crazyhouse.board.String()
+    if !strings.Contains(board, "White Pocket: ["+WhitePawn+" ]") || !strings.Contains(board, "Black Pocket: ["+BlackPawn+" ]") {
+        t.Errorf("pockets not in their owner's color\n%s", board)
+    }
+
+    //Captures in standard chess still show the pieces taken
+    board = standard.board.String()
+    if !strings.Contains(board, "White Captures: ["+BlackPawn+" ]") || !strings.Contains(board, "Black Captures: ["+WhitePawn+" ]") {
+        t.Errorf("captures not in the captured pieces' color\n%s", board)
+    }
+}
+
+func TestCrazyhouseIsNotClassified(t *testing.T) {
+    crazyhouse, standard := NewCrazyhouse(), newTestGame(t, StartFEN)
+    for _, game := range []*ChessGame{&crazyhouse, &standard} {
+        playTestMoves(t, game, "e2 e4", "e7 e5", "g1 f3")
+    }
+
+    if opening, found := crazyhouse.Opening(); found {
+        t.Errorf("Crazyhouse game classified as %s %s", opening.ECO, opening.Name)
+    }
+    if _, found := standard.Opening(); !found {
+        t.Error("standard game not classified")
+    }
+    if pgn := crazyhouse.PGN(); strings.Contains(pgn, "[ECO ") {
+        t.Errorf("ECO tag in the Crazyhouse PGN\n%s", pgn)
+    }
+}
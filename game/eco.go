@@ -0,0 +1,152 @@
+package game
+
+import (
+    "strings"
+    "sync"
+)
+
+// MARK: Opening (ECO classification)
+type Opening struct {
+    ECO  string //Encyclopaedia of Chess Openings code, e.g. "C60"
+    Name string
+}
+
+func (opening Opening) String() string {
+    if opening.ECO == "" {
+        return ""
+    }
+    return opening.ECO + " " + opening.Name
+}
+
+// Bundled ECO table with the main openings, moves as entered at the prompt ("e2e4" for "e2 e4", "e1g1" for O-O).
+var ecoTable = []struct {
+    eco, name, moves string
+}{
+    {"A00", "Polish Opening", "b2b4"},
+    {"A01", "Nimzo-Larsen Attack", "b2b3"},
+    {"A02", "Bird's Opening", "f2f4"},
+    {"A04", "Réti Opening", "g1f3"},
+    {"A10", "English Opening", "c2c4"},
+    {"A20", "English Opening: King's English Variation", "c2c4 e7e5"},
+    {"A30", "English Opening: Symmetrical Variation", "c2c4 c7c5"},
+    {"A40", "Queen's Pawn Game", "d2d4"},
+    {"A43", "Old Benoni Defense", "d2d4 c7c5"},
+    {"A45", "Indian Defense", "d2d4 g8f6"},
+    {"A56", "Benoni Defense", "d2d4 g8f6 c2c4 c7c5"},
+    {"A57", "Benko Gambit", "d2d4 g8f6 c2c4 c7c5 d4d5 b7b5"},
+    {"A80", "Dutch Defense", "d2d4 f7f5"},
+    {"B00", "King's Pawn Game", "e2e4"},
+    {"B01", "Scandinavian Defense", "e2e4 d7d5"},
+    {"B02", "Alekhine Defense", "e2e4 g8f6"},
+    {"B06", "Modern Defense", "e2e4 g7g6"},
+    {"B07", "Pirc Defense", "e2e4 d7d6 d2d4 g8f6"},
+    {"B10", "Caro-Kann Defense", "e2e4 c7c6"},
+    {"B12", "Caro-Kann Defense: Advance Variation", "e2e4 c7c6 d2d4 d7d5 e4e5"},
+    {"B13", "Caro-Kann Defense: Exchange Variation", "e2e4 c7c6 d2d4 d7d5 e4d5"},
+    {"B20", "Sicilian Defense", "e2e4 c7c5"},
+    {"B21", "Sicilian Defense: Smith-Morra Gambit", "e2e4 c7c5 d2d4 c5d4 c2c3"},
+    {"B22", "Sicilian Defense: Alapin Variation", "e2e4 c7c5 c2c3"},
+    {"B23", "Sicilian Defense: Closed", "e2e4 c7c5 b1c3"},
+    {"B27", "Sicilian Defense", "e2e4 c7c5 g1f3"},
+    {"B30", "Sicilian Defense: Old Sicilian", "e2e4 c7c5 g1f3 b8c6"},
+    {"B40", "Sicilian Defense: French Variation", "e2e4 c7c5 g1f3 e7e6"},
+    {"B50", "Sicilian Defense", "e2e4 c7c5 g1f3 d7d6"},
+    {"B70", "Sicilian Defense: Dragon Variation", "e2e4 c7c5 g1f3 d7d6 d2d4 c5d4 f3d4 g8f6 b1c3 g7g6"},
+    {"B90", "Sicilian Defense: Najdorf Variation", "e2e4 c7c5 g1f3 d7d6 d2d4 c5d4 f3d4 g8f6 b1c3 a7a6"},
+    {"C00", "French Defense", "e2e4 e7e6"},
+    {"C01", "French Defense: Exchange Variation", "e2e4 e7e6 d2d4 d7d5 e4d5"},
+    {"C02", "French Defense: Advance Variation", "e2e4 e7e6 d2d4 d7d5 e4e5"},
+    {"C03", "French Defense: Tarrasch Variation", "e2e4 e7e6 d2d4 d7d5 b1d2"},
+    {"C10", "French Defense: Paulsen Variation", "e2e4 e7e6 d2d4 d7d5 b1c3"},
+    {"C11", "French Defense: Classical Variation", "e2e4 e7e6 d2d4 d7d5 b1c3 g8f6"},
+    {"C15", "French Defense: Winawer Variation", "e2e4 e7e6 d2d4 d7d5 b1c3 f8b4"},
+    {"C20", "King's Pawn Game", "e2e4 e7e5"},
+    {"C23", "Bishop's Opening", "e2e4 e7e5 f1c4"},
+    {"C25", "Vienna Game", "e2e4 e7e5 b1c3"},
+    {"C30", "King's Gambit", "e2e4 e7e5 f2f4"},
+    {"C33", "King's Gambit Accepted", "e2e4 e7e5 f2f4 e5f4"},
+    {"C40", "King's Knight Opening", "e2e4 e7e5 g1f3"},
+    {"C41", "Philidor Defense", "e2e4 e7e5 g1f3 d7d6"},
+    {"C42", "Petrov's Defense", "e2e4 e7e5 g1f3 g8f6"},
+    {"C44", "King's Knight Opening: Normal Variation", "e2e4 e7e5 g1f3 b8c6"},
+    {"C44", "Scotch Game", "e2e4 e7e5 g1f3 b8c6 d2d4"},
+    {"C46", "Three Knights Opening", "e2e4 e7e5 g1f3 b8c6 b1c3"},
+    {"C47", "Four Knights Game", "e2e4 e7e5 g1f3 b8c6 b1c3 g8f6"},
+    {"C50", "Italian Game", "e2e4 e7e5 g1f3 b8c6 f1c4"},
+    {"C50", "Italian Game: Giuoco Piano", "e2e4 e7e5 g1f3 b8c6 f1c4 f8c5"},
+    {"C51", "Italian Game: Evans Gambit", "e2e4 e7e5 g1f3 b8c6 f1c4 f8c5 b2b4"},
+    {"C55", "Italian Game: Two Knights Defense", "e2e4 e7e5 g1f3 b8c6 f1c4 g8f6"},
+    {"C60", "Ruy Lopez", "e2e4 e7e5 g1f3 b8c6 f1b5"},
+    {"C65", "Ruy Lopez: Berlin Defense", "e2e4 e7e5 g1f3 b8c6 f1b5 g8f6"},
+    {"C68", "Ruy Lopez: Exchange Variation", "e2e4 e7e5 g1f3 b8c6 f1b5 a7a6 b5c6"},
+    {"C70", "Ruy Lopez: Morphy Defense", "e2e4 e7e5 g1f3 b8c6 f1b5 a7a6"},
+    {"C84", "Ruy Lopez: Closed", "e2e4 e7e5 g1f3 b8c6 f1b5 a7a6 b5a4 g8f6 e1g1 f8e7"},
+    {"D00", "Queen's Pawn Game", "d2d4 d7d5"},
+    {"D06", "Queen's Gambit", "d2d4 d7d5 c2c4"},
+    {"D07", "Queen's Gambit Declined: Chigorin Defense", "d2d4 d7d5 c2c4 b8c6"},
+    {"D10", "Slav Defense", "d2d4 d7d5 c2c4 c7c6"},
+    {"D20", "Queen's Gambit Accepted", "d2d4 d7d5 c2c4 d5c4"},
+    {"D30", "Queen's Gambit Declined", "d2d4 d7d5 c2c4 e7e6"},
+    {"D80", "Grünfeld Defense", "d2d4 g8f6 c2c4 g7g6 b1c3 d7d5"},
+    {"E12", "Queen's Indian Defense", "d2d4 g8f6 c2c4 e7e6 g1f3 b7b6"},
+    {"E20", "Nimzo-Indian Defense", "d2d4 g8f6 c2c4 e7e6 b1c3 f8b4"},
+    {"E60", "King's Indian Defense", "d2d4 g8f6 c2c4 g7g6"},
+}
+
+var (
+    ecoPositions     map[string]Opening //position key to opening, see getPositionKey()
+    ecoPositionsOnce sync.Once
+)
+
+// Get the opening of the position, false if it's not in the table.
+// Positions are compared instead of moves, so transpositions are found too.
+// The table is for standard chess, Crazyhouse positions are never classified.
+func ClassifyPosition(position Position) (Opening, bool) {
+    if position.board.dropsAllowed {
+        return Opening{}, false
+    }
+    ecoPositionsOnce.Do(buildECOPositions)
+    opening, found := ecoPositions[getPositionKey(position.board, position.sideToMove)]
+    return opening, found
+}
+
+// Play every line of the table from the standard start and index the positions reached
+func buildECOPositions() {
+    ecoPositions = make(map[string]Opening)
+    for _, entry := range ecoTable {
+        position, err := NewPositionFromFEN(StartFEN)
+        for _, move := range strings.Fields(entry.moves) {
+            if err != nil {
+                break
+            }
+            position, _, err = position.Play(move[:2] + " " + move[2:])
+        }
+        if err != nil {
+            panic("invalid ECO line " + entry.eco + " " + entry.name + ": " + err.Error())
+        }
+        ecoPositions[getPositionKey(position.board, position.sideToMove)] = Opening{entry.eco, entry.name}
+    }
+}
+
+// Pieces and side to move identify the opening, castling rights are left out so transpositions still match
+func getPositionKey(board *Board, sideToMove Team) string {
+    return board.FENPlacement() + " " + sideToMove.String()
+}
+
+// Get the opening of the game, from the last position played that is in the table
+func (game ChessGame) Opening() (Opening, bool) {
+    if len(game.openings) == 0 {
+        return Opening{}, false
+    }
+    opening := game.openings[len(game.openings)-1]
+    return opening, opening.ECO != ""
+}
+
+// Classify the position after a move, keeping the previous opening if the position isn't in the table
+func (game *ChessGame) updateOpening() {
+    opening, _ := game.Opening()
+    if found, ok := ClassifyPosition(game.Position()); ok {
+        opening = found
+    }
+    game.openings = append(game.openings, opening)
+}
@@ -0,0 +1,113 @@
+package game
+
+import (
+    "errors"
+    "strconv"
+    "strings"
+)
+
+// Standard starting position
+const StartFEN = "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1"
+
+// Create a board from the piece placement field of a FEN string, the other fields are ignored
+func NewBoardFromFEN(fen string) (*Board, error) {
+    fields := strings.Fields(fen)
+    if len(fields) == 0 {
+        return nil, errors.New("empty FEN")
+    }
+
+    //Crazyhouse pockets in brackets after the placement
+    placement, pocket := fields[0], ""
+    hasPocket := false
+    if start := strings.Index(placement, "["); start >= 0 && strings.HasSuffix(placement, "]") {
+        placement, pocket, hasPocket = placement[:start], placement[start+1:len(placement)-1], true
+    }
+
+    ranks := strings.Split(placement, "/")
+    if len(ranks) != boardSize {
+        return nil, errors.New("FEN placement must have 8 ranks: " + placement)
+    }
+
+    board := NewBoard()
+    for row, rank := range ranks { //row 0 is rank 8, same as FEN
+        col := 0
+        for _, char := range rank {
+            if char >= '1' && char <= '8' {
+                col += int(char - '0')
+                continue
+            }
+            if !strings.ContainsRune("kqrbnpKQRBNP", char) {
+                return nil, errors.New("unknown piece in FEN: " + string(char))
+            }
+            if col >= boardSize {
+                return nil, errors.New("FEN rank must have 8 squares: " + rank)
+            }
+            //FEN uses upper case for White, the board uses lower case for White
+            piece := createPiece(getPieceLetter(string(char)), row, col)
+            board.squares[row][col].setPiece(&piece)
+            col++
+        }
+        if col != boardSize {
+            return nil, errors.New("FEN rank must have 8 squares: " + rank)
+        }
+    }
+
+    for _, team := range []Team{white, black} {
+        if count := board.countKings(team); count != 1 {
+            return nil, errors.New(getTeamName(team) + " must have exactly one King, found " + strconv.Itoa(count))
+        }
+    }
+
+    if hasPocket {
+        board.dropsAllowed = true
+        for _, char := range pocket {
+            if !strings.ContainsRune("QRBNPqrbnp", char) {
+                return nil, errors.New("unknown piece in FEN pocket: " + string(char))
+            }
+            //The letter in FEN case is the sign of the captured piece in the board's case, see FENPocket()
+            if char >= 'A' && char <= 'Z' {
+                board.whiteCaptures = append(board.whiteCaptures, string(char))
+            } else {
+                board.blackCaptures = append(board.blackCaptures, string(char))
+            }
+        }
+    }
+
+    return board, nil
+}
+
+// Get the piece placement field of the FEN string of the board
+func (board Board) FENPlacement() string {
+    var ranks []string
+    for i := 0; i < boardSize; i++ {
+        rank := ""
+        empty := 0
+        for j := 0; j < boardSize; j++ {
+            piece := board.squares[i][j].getPiece()
+            if piece == nil {
+                empty++
+                continue
+            }
+            if empty > 0 {
+                rank += strconv.Itoa(empty)
+                empty = 0
+            }
+            rank += getPieceLetter(piece.sign)
+        }
+        if empty > 0 {
+            rank += strconv.Itoa(empty)
+        }
+        ranks = append(ranks, rank)
+    }
+    return strings.Join(ranks, "/")
+}
+
+func (board Board) countKings(team Team) int {
+    count := 0
+    for _, piece := range board.getAllPieces(team) {
+        if isKing(piece) {
+            count++
+        }
+    }
+    return count
+}
@@ -0,0 +1,81 @@
+package game
+
+import (
+    "strings"
+    "testing"
+)
+
+func TestFENRoundTrip(t *testing.T) {
+    fens := []string{
+        StartFEN,
+        "rnbqkbnr/pppp1ppp/8/4p3/4P3/5N2/PPPP1PPP/RNBQKB1R b KQkq - 1 2",
+        "r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1",
+        "8/2p5/3p4/KP5r/1R3p1k/8/4P1P1/8 w - - 0 1",
+        "4k3/8/8/8/8/8/4P3/4K3 b - - 7 10",
+        "bqnb1rkr/pp3ppp/3ppn2/2p5/5P2/P2P4/NPP1P1PP/BQ1BNRKR w KQkq - 2 9",
+        "rnbqkb1r/ppp1pppp/5n2/3P4/8/8/PPPP1PPP/RNBQKBNR[Pp] b KQkq - 0 3",
+    }
+
+    for _, fen := range fens {
+        game, err := NewFromFEN(fen)
+        if err != nil {
+            t.Errorf("%s: %v", fen, err)
+            continue
+        }
+        if written := game.FEN(); written != fen {
+            t.Errorf("read %s, wrote %s", fen, written)
+        }
+
+        board, err := NewBoardFromFEN(fen)
+        if err != nil {
+            t.Errorf("%s: %v", fen, err)
+            continue
+        }
+        if placement := board.FENPlacement(); placement != strings.Split(strings.Fields(fen)[0], "[")[0] {
+            t.Errorf("%s: placement %s", fen, placement)
+        }
+    }
+}
+
+func TestFENDefaults(t *testing.T) {
+    //Only the placement is needed, White moves first without castling rights
+    game, err := NewFromFEN("4k3/8/8/8/8/8/8/4K3")
+    if err != nil {
+        t.Fatal(err)
+    }
+    if fen := game.FEN(); fen != "4k3/8/8/8/8/8/8/4K3 w - - 0 1" {
+        t.Errorf("FEN with the default fields: %s", fen)
+    }
+}
+
+func TestInvalidFEN(t *testing.T) {
+    tests := []struct {
+        name string
+        fen  string
+    }{
+        {"empty", ""},
+        {"7 ranks", "rnbqkbnr/pppppppp/8/8/8/PPPPPPPP/RNBQKBNR w - - 0 1"},
+        {"9 ranks", "rnbqkbnr/pppppppp/8/8/8/8/8/PPPPPPPP/RNBQKBNR w - - 0 1"},
+        {"short rank", "rnbqkbnr/pppppppp/8/8/7/8/PPPPPPPP/RNBQKBNR w - - 0 1"},
+        {"long rank", "rnbqkbnr/pppppppp/8/8/44P/8/PPPPPPPP/RNBQKBNR w - - 0 1"},
+        {"piece past the last file", "rnbqkbnr/ppppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w - - 0 1"},
+        {"unknown piece", "rnbqkbnr/pppppppp/8/8/3X4/8/PPPPPPPP/RNBQKBNR w - - 0 1"},
+        {"missing White King", "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQ1BNR w - - 0 1"},
+        {"missing Black King", "rnbq1bnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w - - 0 1"},
+        {"two Kings", "rnbqkbnr/pppppppp/8/8/4K3/8/PPPPPPPP/RNBQKBNR w - - 0 1"},
+        {"bad side field", "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR x - - 0 1"},
+        {"upper case side field", "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR W - - 0 1"},
+        {"bad castling field", "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KX - 0 1"},
+        {"negative halfmove clock", "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w - - -1 1"},
+        {"halfmove clock not a number", "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w - - x 1"},
+        {"move number 0", "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w - - 0 0"},
+        {"side not to move in check", "4k3/8/8/8/8/8/8/r3K3 b - - 0 1"},
+        {"King in the pocket", "4k3/8/8/8/8/8/8/4K3[K] w - - 0 1"},
+    }
+
+    for _, test := range tests {
+        if _, err := NewFromFEN(test.fen); err == nil {
+            t.Errorf("%s: %q accepted", test.name, test.fen)
+        }
+    }
+}
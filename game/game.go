@@ -6,6 +6,9 @@ import (
     "os"
     "github.com/dilyar85/chess/utils"
     "strings"
+    "time"
+    "io"
+    "errors"
 )
 
 const (
@@ -13,11 +16,24 @@ const (
     InitialBoardFileName = "./playbook/initialBoard.txt"
     illegalMoveMessage   = "Illegal move! Please enter again."
     causingSelfInCheckMessage = "This move will cause yourself in check! Please enter again."
+    noDrawOfferMessage   = "There is no draw offer to answer."
+    gameOverMessage      = "The game is over."
+    noMoveToUndoMessage  = "There is no move to take back."
+
+    resignCommand  = "resign"
+    drawCommand    = "draw"
+    acceptCommand  = "accept"
+    declineCommand = "decline"
+    abortCommand   = "abort"
+    historyCommand = "history"
+    selectCommand  = "select"
+    flipCommand    = "flip"
+    pgnCommand     = "pgn"
 
 )
 
 func New() ChessGame {
-    game := ChessGame{NewBoard(), 0, undecided, *bufio.NewReader(os.Stdin)}
+    game := ChessGame{board: NewBoard(), curTeam: undecided}
     return game
 }
 
@@ -26,22 +42,72 @@ type ChessGame struct {
     board      *Board
     movesCount int
     curTeam    Team
-    inputReader bufio.Reader
+    inputs     <-chan string
+    clocks     map[Team]*Clock //nil when playing without time control
+    drawOfferedBy Team         //undecided when there is no pending draw offer
+    outcome    *Outcome        //nil while the game is in progress
+    history    MoveHistory
+    openings   []Opening       //opening after each move, same length as history
+    renderer   utils.Renderer
+    flipped    bool            //board is drawn from Black's side
+    autoFlip   bool            //board is drawn from the side to move
+    startMoveNumber int        //full move number of the first move, 0 for 1 (games created from FEN)
+    startHalfMoves  int        //halfmove clock of the FEN the game was created from
+    blackStarts     bool       //BLACK Player made the first move (games created from FEN)
+    peer            *peer      //opponent over the network, nil when both players use this terminal
+    localTeam       Team       //team of the player at this terminal in a network game
+    startFEN        string     //FEN the game was created from, "" for the standard start
+    variant         string     //"" for standard chess, "Chess960" or "Crazyhouse"
+    fromFEN         bool       //created by NewFromFEN(), the board is set up already
 
 }
 
-func (game *ChessGame) StartInteractiveMode() {
+// Set how the board is drawn in the terminal
+func (game *ChessGame) SetRenderer(renderer utils.Renderer) {
+    game.renderer = renderer
+}
+
+// Draw the board from the perspective of the player to move (for hot-seat play)
+func (game *ChessGame) SetAutoFlip(autoFlip bool) {
+    game.autoFlip = autoFlip
+}
+
+// Play with a chess clock for each player
+func (game *ChessGame) SetTimeControl(control TimeControl) {
+    game.clocks = map[Team]*Clock{
+        white: NewClock(control),
+        black: NewClock(control),
+    }
+}
+
+// Play the game from the terminal and return how it ended
+func (game *ChessGame) StartInteractiveMode() Outcome {
 
-    game.setupBoard(InitialBoardFileName)
+    if !game.fromFEN {
+        game.setupBoard(InitialBoardFileName)
+    }
+    game.inputs = readLines(os.Stdin)
+    if game.variant != "" {
+        fmt.Println("Variant:", game.variant)
+    }
+    if game.clocks != nil {
+        fmt.Println("Time control:", game.clocks[white].control)
+    }
     game.printGameStatus()
 
     for {
         game.changeTurn(true)
         game.printAvailableMovesInCheck()
-        input := game.promptInput(game.inputReader)
+        game.printDrawOffer()
+        game.startClock()
+        input, inTime := game.promptInput()
+        if !inTime {
+            game.endGameOnTime()
+            return *game.outcome
+        }
         gameEnd := game.execute(input)
         if gameEnd {
-            return
+            return *game.outcome
         }
     }
 
@@ -77,15 +143,22 @@ func (game *ChessGame) execute(command string)  bool {
         }
     }()
 
-    checkmate := game.board.execute(command, game.curTeam)
+    if handled, gameEnd := game.executeGameCommand(command); handled {
+        return gameEnd
+    }
+
+    outcome := game.makeMove(command)
+    flagged := game.stopClock()
+    game.sendToPeer(command)
 
-    if checkmate {
-        game.endGameWithWinner(getTeamName(game.curTeam), "Checkmate", command)
+    if flagged {
+        game.printAction(command)
+        game.endGameOnTime()
         return true
     }
 
-    if game.isTie() {
-        game.endGameByTie(command)
+    if outcome != nil {
+        game.endGame(*outcome, command)
         return true
     }
 
@@ -95,12 +168,171 @@ func (game *ChessGame) execute(command string)  bool {
 }
 
 
-func (game ChessGame) promptInput(reader bufio.Reader) string {
+// Make the move of the current team, return the outcome if the move ends the game
+func (game *ChessGame) makeMove(command string) *Outcome {
+    san, checkmate := game.board.execute(command, game.curTeam)
+    game.history = append(game.history, san)
+    game.updateOpening()
+
+    //Moving instead of answering declines the opponent's draw offer
+    opponent := getOpponentTeam(game.curTeam)
+    if game.drawOfferedBy == opponent {
+        game.drawOfferedBy = undecided
+    }
+
+    var outcome Outcome
+    switch {
+    case checkmate:
+        outcome = newWin(game.curTeam, Checkmate)
+    case game.board.inStalemate(opponent):
+        outcome = newDraw(Stalemate)
+    case game.isTie():
+        outcome = newDraw(MoveLimit)
+    default:
+        return nil
+    }
+    return &outcome
+}
+
+// Execute commands other than moves (resign, draw offers and abort).
+// Return whether the command was handled and if the game should end.
+func (game *ChessGame) executeGameCommand(command string) (handled bool, gameEnd bool) {
+    curTeam := game.curTeam
+    opponent := getOpponentTeam(curTeam)
+
+    if tokens := strings.Fields(command); len(tokens) == 2 && tokens[0] == selectCommand {
+        if square := game.board.getSquare(tokens[1]); square == nil || !square.hasPiece() {
+            panic("There is no piece to select at " + tokens[1] + ".")
+        }
+        game.changeTurn(false) //still has to move, hand the turn back first so the board is drawn for the side to move
+        game.printSelection(tokens[1])
+        return true, false
+    }
+
+    switch strings.TrimSpace(command) {
+    case resignCommand:
+        game.sendToPeer(command)
+        game.endGame(newWin(opponent, Resignation), command)
+        return true, true
+
+    case drawCommand:
+        if game.drawOfferedBy == opponent {
+            return game.executeGameCommand(acceptCommand) //both players want a draw
+        }
+        game.drawOfferedBy = curTeam
+        game.sendToPeer(command)
+        fmt.Println(getTeamName(curTeam), "offers a draw.")
+        game.changeTurn(false) //still has to move
+        return true, false
+
+    case acceptCommand:
+        if game.drawOfferedBy != opponent {
+            panic(noDrawOfferMessage)
+        }
+        game.sendToPeer(command)
+        game.endGame(newDraw(Agreement), command)
+        return true, true
+
+    case declineCommand:
+        if game.drawOfferedBy != opponent {
+            panic(noDrawOfferMessage)
+        }
+        game.drawOfferedBy = undecided
+        game.sendToPeer(command)
+        fmt.Println(getTeamName(curTeam), "declines the draw offer.")
+        game.changeTurn(false) //still has to move
+        return true, false
+
+    case historyCommand:
+        game.printHistory()
+        game.changeTurn(false) //still has to move
+        return true, false
+
+    case pgnCommand:
+        fmt.Println(game.PGN())
+        game.changeTurn(false) //still has to move
+        return true, false
+
+    case flipCommand:
+        game.flipped = !game.flipped
+        game.changeTurn(false) //still has to move, hand the turn back first so the board is drawn for the side to move
+        game.printGameStatus()
+        return true, false
+
+    case abortCommand:
+        game.sendToPeer(command)
+        game.endGame(Outcome{undecided, Aborted}, command)
+        return true, true
+    }
+
+    return false, false
+}
+
+func (game ChessGame) printDrawOffer() {
+    if game.drawOfferedBy == getOpponentTeam(game.curTeam) {
+        fmt.Println(getTeamName(game.drawOfferedBy), "offers a draw. Enter \"" + acceptCommand + "\" or \"" + declineCommand + "\", or make a move to decline.")
+    }
+}
+
+// Wait for the next input line, return false if the current player's flag falls first
+func (game ChessGame) promptInput() (string, bool) {
     fmt.Print(getTeamName(game.curTeam), "> ")
-    input, _ := reader.ReadString('\n')
-    input = strings.TrimRight(input, "\n") //remove "\n" from input
 
-    return input
+    var timeout <-chan time.Time //nil channel never fires when playing without clocks
+    if clock := game.clocks[game.curTeam]; clock != nil {
+        timer := time.NewTimer(clock.Remaining(time.Now()))
+        defer timer.Stop()
+        timeout = timer.C
+    }
+
+    select {
+    case input, ok := <-game.inputs:
+        if !ok {
+            return abortCommand, true //no more input
+        }
+        return input, true
+    case <-timeout:
+        fmt.Println()
+        return "", false
+    }
+}
+
+// Read lines in background so that waiting for input can be interrupted by the clock
+func readLines(reader io.Reader) <-chan string {
+    lines := make(chan string)
+    go func() {
+        bufReader := bufio.NewReader(reader)
+        for {
+            input, err := bufReader.ReadString('\n')
+            if input != "" || err == nil {
+                lines <- strings.TrimRight(input, "\n") //remove "\n" from input
+            }
+            if err != nil {
+                close(lines)
+                return
+            }
+        }
+    }()
+    return lines
+}
+
+// Start current player's clock, the opponent's clock in a network game only runs for display
+func (game ChessGame) startClock() {
+    if clock := game.clocks[game.curTeam]; clock != nil {
+        clock.Start(time.Now())
+    }
+}
+
+// Stop current player's clock after a move, return true if the flag has fallen
+func (game ChessGame) stopClock() bool {
+    clock := game.clocks[game.curTeam]
+    if game.isRemote(game.curTeam) {
+        if clock != nil {
+            clock.pause(time.Now()) //the opponent's time left comes with their CLOCK message
+        }
+        return false
+    }
+    return clock != nil && clock.Stop(time.Now())
 }
 
 
@@ -132,7 +364,7 @@ func (game ChessGame) printAvailableMovesInCheck() {
 
     fmt.Println(getTeamName(game.curTeam) + " is in check!")
     fmt.Println("Available moves:")
-    availableMoves := game.board.getAvailableMovesInCheck(curTeam)
+    availableMoves := game.board.getAvailableMoves(curTeam)
     for _, move := range availableMoves {
         fmt.Println(move)
     }
@@ -145,22 +377,124 @@ func (game ChessGame) isTie() bool {
 }
 
 
-func (game ChessGame) endGameWithWinner(winnerPlayer string,  reason interface{}, lastCommand string) {
-    game.printAction(lastCommand)
+// Record how the game ended and print the final status
+func (game *ChessGame) endGame(outcome Outcome, lastCommand string) {
+    game.outcome = &outcome
+    if lastCommand != "" {
+        game.printAction(lastCommand)
+    }
     game.printGameStatus()
     fmt.Println()
-    fmt.Println(winnerPlayer, "player wins. ", reason)
+    fmt.Println(outcome)
 }
 
-func (game ChessGame) endGameByTie(lastCommand string) {
-    game.printAction(lastCommand)
-    game.printGameStatus()
-    fmt.Println("Tie game.  Too many moves.")
+// Current player ran out of time
+func (game *ChessGame) endGameOnTime() {
+    fmt.Println(getTeamName(game.curTeam), "ran out of time.")
+    game.endGame(game.getTimeoutOutcome(game.curTeam), "")
+}
+
+// The team ran out of time, it's a tie if the opponent cannot checkmate anymore
+func (game ChessGame) getTimeoutOutcome(team Team) Outcome {
+    opponent := getOpponentTeam(team)
+    if !game.board.hasMatingMaterial(opponent) {
+        return newDraw(InsufficientMaterial)
+    }
+    return newWin(opponent, Timeout)
 }
 
 
 func (game ChessGame) printGameStatus() {
-    fmt.Println(game.board.String())
+    game.printBoard(nil)
+    if opening, found := game.Opening(); found {
+        fmt.Println("Opening:", opening)
+    }
+    if len(game.history) > 0 {
+        fmt.Println("Moves:", game.history.recent(recentMovesCount, game.startMoveNumber, game.blackStarts))
+        fmt.Println()
+    }
+}
+
+// Print the board highlighting the last move, a King in check and the extra highlights
+func (game ChessGame) printBoard(extraHighlights map[string]utils.Highlight) {
+    fmt.Println(game.boardString(extraHighlights))
+}
+
+// Get the board as printBoard() shows it, from Black's side when it's flipped
+func (game ChessGame) boardString(extraHighlights map[string]utils.Highlight) string {
+    highlights := make(map[string]utils.Highlight)
+    for _, position := range game.board.getLastMove() {
+        highlights[position] = utils.LastMoveHighlight
+    }
+    for _, team := range []Team{white, black} {
+        if game.board.inCheck(team) {
+            highlights[game.board.getKingPosition(team)] = utils.CheckHighlight
+        }
+    }
+    for position, highlight := range extraHighlights {
+        highlights[position] = highlight
+    }
+
+    renderer := game.renderer
+    renderer.Flipped = game.isFlipped()
+    boardStr := game.board.render(renderer, highlights)
+    if game.clocks != nil {
+        boardStr = game.attachClocks(boardStr)
+    }
+    return boardStr
+}
+
+// Check if the board should be drawn from Black's side, the side to move comes from curTeam between moves
+func (game ChessGame) isFlipped() bool {
+    blackToMove := game.SideToMove() == black
+    return game.flipped != (game.autoFlip && blackToMove)
+}
+
+// Print the board with the piece at the position and the squares the side to move can move it to highlighted
+func (game ChessGame) printSelection(position string) {
+    highlights := map[string]utils.Highlight{position: utils.SelectedHighlight}
+    for _, move := range game.board.getLegalMoves(game.SideToMove()) {
+        tokens := strings.Split(move, " ")
+        if tokens[0] == position {
+            highlights[tokens[1]] = utils.TargetHighlight
+        }
+    }
+    game.printBoard(highlights)
+}
+
+func (game ChessGame) printHistory() {
+    if len(game.history) == 0 {
+        fmt.Println("No moves yet.")
+        return
+    }
+    for _, line := range game.history.lines(game.startMoveNumber, game.blackStarts) {
+        fmt.Println(line)
+    }
+}
+
+// Show each player's remaining time to the right of the board, next to their own side
+func (game ChessGame) attachClocks(boardStr string) string {
+    lines := strings.Split(boardStr, "\n")
+
+    var rankLines []int
+    for i, line := range lines {
+        if len(line) > 0 && line[0] >= '1' && line[0] <= '8' {
+            rankLines = append(rankLines, i)
+        }
+    }
+    if len(rankLines) == 0 {
+        return boardStr
+    }
+
+    topTeam, bottomTeam := black, white
+    if game.isFlipped() {
+        topTeam, bottomTeam = white, black
+    }
+    top, bottom := rankLines[0], rankLines[len(rankLines)-1]
+    lines[top] += "   " + getTeamName(topTeam) + " " + game.clocks[topTeam].String()
+    lines[bottom] += "   " + getTeamName(bottomTeam) + " " + game.clocks[bottomTeam].String()
+
+    return strings.Join(lines, "\n")
 }
 
 func (game ChessGame) printAction(action string) {
@@ -176,6 +510,32 @@ const (
     black     Team = iota
 )
 
+// Team name as used by programs, "white" or "black"
+func (team Team) String() string {
+    switch team {
+    case white:
+        return "white"
+    case black:
+        return "black"
+
+    default:
+        return ""
+    }
+}
+
+// Get the team from its name ("white" or "black")
+func ParseTeam(name string) (Team, error) {
+    switch strings.ToLower(name) {
+    case "white", "w":
+        return white, nil
+    case "black", "b":
+        return black, nil
+
+    default:
+        return undecided, errors.New("unknown side: " + name)
+    }
+}
+
 func getTeamName(team Team) string {
     switch team {
     case white:
@@ -0,0 +1,67 @@
+package game
+
+import (
+    "strings"
+    "testing"
+)
+
+// Get the file letters of the top line and the ranks from top to bottom of the board as the game draws it
+func getBoardOrientation(game ChessGame) (files, ranks string) {
+    lines := strings.Split(game.boardString(nil), "\n")
+    files = strings.Join(strings.Fields(lines[0]), "")
+    for _, line := range lines {
+        if len(line) > 0 && line[0] >= '1' && line[0] <= '8' {
+            ranks += line[:1]
+        }
+    }
+    return files, ranks
+}
+
+func TestFlipAndAutoFlip(t *testing.T) {
+    const whiteSide, blackSide = "abcdefgh87654321", "hgfedcba12345678"
+    tests := []struct {
+        name     string
+        fen      string
+        moves    []string
+        flipped  bool
+        autoFlip bool
+        expected string //files then ranks as drawn
+    }{
+        {"White's side", StartFEN, nil, false, false, whiteSide},
+        {"flipped", StartFEN, nil, true, false, blackSide},
+        {"auto-flip with White to move", StartFEN, nil, false, true, whiteSide},
+        {"auto-flip with Black to move", StartFEN, []string{"e2 e4"}, false, true, blackSide},
+        {"flip and auto-flip cancel out", StartFEN, []string{"e2 e4"}, true, true, whiteSide},
+        {"flip without auto-flip stays", StartFEN, []string{"e2 e4"}, true, false, blackSide},
+        {"auto-flip in a game Black starts", "4k3/8/8/8/8/8/4P3/4K3 b - - 0 10", nil, false, true, blackSide},
+        {"auto-flip after Black's first move", "4k3/8/8/8/8/8/4P3/4K3 b - - 0 10", []string{"e8 d8"}, false, true, whiteSide},
+        {"auto-flip two moves into a game Black starts", "4k3/8/8/8/8/8/4P3/4K3 b - - 0 10", []string{"e8 d8", "e2 e4"}, false, true, blackSide},
+    }
+
+    for _, test := range tests {
+        game := newTestGame(t, test.fen)
+        for _, move := range test.moves {
+            if _, err := game.Move(move); err != nil {
+                t.Fatalf("%s: %s %v", test.name, move, err)
+            }
+        }
+        game.flipped, game.autoFlip = test.flipped, test.autoFlip
+        if files, ranks := getBoardOrientation(game); files+ranks != test.expected {
+            t.Errorf("%s: files %s ranks %s, expected %s", test.name, files, ranks, test.expected)
+        }
+    }
+}
+
+func TestFlipAndSelectKeepTheTurn(t *testing.T) {
+    game := newTestGame(t, StartFEN)
+    game.autoFlip = true
+    playCommands(&game, "e2 e4", "flip", "select e7", "select e5", "select e", "e7 e5") //nothing to select on e5 or "e"
+
+    if len(game.history) != 2 || game.history[1] != "e5" {
+        t.Fatalf("Black must still move after flip and select: %v", game.history)
+    }
+    //Flipped by hand, auto-flip adds nothing with White to move
+    if !game.flipped || !game.isFlipped() {
+        t.Errorf("flipped %v, drawn from Black's side %v", game.flipped, game.isFlipped())
+    }
+}
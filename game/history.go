@@ -0,0 +1,60 @@
+package game
+
+import (
+    "strconv"
+    "strings"
+)
+
+const recentMovesCount = 8 //full moves shown under the board
+
+// MARK: MoveHistory (moves played in SAN, in the order they were made)
+type MoveHistory []string
+
+// Get the moves with their numbers as PGN writes them, e.g. "10...", "Kd8", "11.", "e4".
+// The first move has the start move number (0 for 1), and is Black's when blackStarts.
+func (history MoveHistory) numberedTokens(startMoveNumber int, blackStarts bool) []string {
+    moveNumber := startMoveNumber
+    if moveNumber == 0 {
+        moveNumber = 1
+    }
+    var tokens []string
+    for i, san := range history {
+        whiteMove := (i%2 == 0) != blackStarts
+        if whiteMove {
+            tokens = append(tokens, strconv.Itoa(moveNumber)+".")
+        } else if i == 0 {
+            tokens = append(tokens, strconv.Itoa(moveNumber)+"...")
+        }
+        tokens = append(tokens, san)
+        if !whiteMove {
+            moveNumber++
+        }
+    }
+    return tokens
+}
+
+// Get one line per move number, e.g. "1. e4 e5", or "10... Kd8" when Black made the first move
+func (history MoveHistory) lines(startMoveNumber int, blackStarts bool) []string {
+    var lines []string
+    for _, token := range history.numberedTokens(startMoveNumber, blackStarts) {
+        if strings.HasSuffix(token, ".") { //a move number starts the next line
+            lines = append(lines, token)
+        } else {
+            lines[len(lines)-1] += " " + token
+        }
+    }
+    return lines
+}
+
+// Get the last full moves on one line, with "..." in front if earlier moves are left out
+func (history MoveHistory) recent(count, startMoveNumber int, blackStarts bool) string {
+    lines := history.lines(startMoveNumber, blackStarts)
+    if len(lines) <= count {
+        return strings.Join(lines, " ")
+    }
+    return "... " + strings.Join(lines[len(lines)-count:], " ")
+}
+
+func (history MoveHistory) String() string {
+    return strings.Join(history.lines(1, false), " ")
+}
@@ -0,0 +1,55 @@
+package game
+
+import (
+    "strings"
+    "testing"
+)
+
+func TestMoveHistoryLines(t *testing.T) {
+    tests := []struct {
+        name            string
+        history         MoveHistory
+        startMoveNumber int
+        blackStarts     bool
+        lines           string //lines joined by " / "
+    }{
+        {"empty", nil, 0, false, ""},
+        {"from the start", MoveHistory{"e4", "e5", "Nf3"}, 0, false, "1. e4 e5 / 2. Nf3"},
+        {"from a later move", MoveHistory{"Kd2", "Kd7"}, 10, false, "10. Kd2 Kd7"},
+        {"Black first", MoveHistory{"Kd8", "e4", "Kc7"}, 10, true, "10... Kd8 / 11. e4 Kc7"},
+        {"Black first at move 1", MoveHistory{"e5"}, 0, true, "1... e5"},
+    }
+
+    for _, test := range tests {
+        if lines := strings.Join(test.history.lines(test.startMoveNumber, test.blackStarts), " / "); lines != test.lines {
+            t.Errorf("%s: %q, expected %q", test.name, lines, test.lines)
+        }
+    }
+}
+
+func TestMoveHistoryRecent(t *testing.T) {
+    history := MoveHistory{"Kd8", "e4", "Kc7", "e5", "Kb6"}
+    if recent := history.recent(2, 10, true); recent != "... 11. e4 Kc7 12. e5 Kb6" {
+        t.Errorf("recent moves: %s", recent)
+    }
+    if recent := history.recent(3, 10, true); recent != "10... Kd8 11. e4 Kc7 12. e5 Kb6" {
+        t.Errorf("all moves: %s", recent)
+    }
+}
+
+func TestHistoryNumberingMatchesPGN(t *testing.T) {
+    game := newTestGame(t, "4k3/8/8/8/8/8/4P3/4K3 b - - 0 10")
+    for _, move := range []string{"e8 d8", "e2 e4"} {
+        if _, err := game.Move(move); err != nil {
+            t.Fatal(err)
+        }
+    }
+
+    lines := game.history.lines(game.startMoveNumber, game.blackStarts)
+    if strings.Join(lines, " ") != "10... Kd8 11. e4" {
+        t.Errorf("history lines: %v", lines)
+    }
+    if pgn := game.PGN(); !strings.Contains(pgn, "\n10... Kd8 11. e4 *") {
+        t.Errorf("PGN movetext differs from the history\n%s", pgn)
+    }
+}
@@ -0,0 +1,300 @@
+package game
+
+import (
+    "errors"
+    "fmt"
+    "image"
+    "image/color"
+    "image/draw"
+    "image/gif"
+    "image/png"
+    "io"
+    "strings"
+    "time"
+    "github.com/dilyar85/chess/utils"
+)
+
+// MARK: ImageOptions (how Board.Image() draws the board)
+type ImageOptions struct {
+    SquareSize                             int //pixels per square
+    LightColor, DarkColor, HighlightColor  color.RGBA
+    WhitePieceColor, BlackPieceColor       color.RGBA
+    OutlineColor                           color.RGBA
+    Flipped                                bool
+}
+
+func DefaultImageOptions() ImageOptions {
+    return ImageOptions{
+        SquareSize:      48,
+        LightColor:      color.RGBA{0xf0, 0xd9, 0xb5, 0xff},
+        DarkColor:       color.RGBA{0xb5, 0x88, 0x63, 0xff},
+        HighlightColor:  color.RGBA{0xcd, 0xd2, 0x6a, 0xff},
+        WhitePieceColor: color.RGBA{0xff, 0xff, 0xff, 0xff},
+        BlackPieceColor: color.RGBA{0x20, 0x20, 0x20, 0xff},
+        OutlineColor:    color.RGBA{0x00, 0x00, 0x00, 0xff},
+    }
+}
+
+// Colors used by Board.Image(), GIF frames are drawn with exactly this palette
+func (options ImageOptions) palette() color.Palette {
+    return color.Palette{options.LightColor, options.DarkColor, options.HighlightColor,
+        options.WhitePieceColor, options.BlackPieceColor, options.OutlineColor}
+}
+
+// Draw the board as an image, highlighting the squares at the given positions (e.g. the last move).
+// Pieces are drawn from built-in bitmaps, so no fonts are needed.
+func (board Board) Image(options ImageOptions, highlightedPositions []string) *image.RGBA {
+    size := options.SquareSize
+    img := image.NewRGBA(image.Rect(0, 0, size*boardSize, size*boardSize))
+
+    highlighted := make(map[string]bool)
+    for _, position := range highlightedPositions {
+        highlighted[position] = true
+    }
+
+    for i := 0; i < boardSize; i++ {
+        for j := 0; j < boardSize; j++ {
+            row, col := i, j
+            if options.Flipped {
+                row, col = boardSize-1-i, boardSize-1-j
+            }
+            rect := image.Rect(col*size, row*size, (col+1)*size, (row+1)*size)
+
+            squareColor := options.DarkColor
+            if (i+j)%2 == 0 {
+                squareColor = options.LightColor
+            }
+            if highlighted[getCoordinatePosition(i, j)] {
+                squareColor = options.HighlightColor
+            }
+            draw.Draw(img, rect, &image.Uniform{squareColor}, image.Point{}, draw.Src)
+
+            if piece := board.squares[i][j].getPiece(); piece != nil {
+                fill := options.BlackPieceColor
+                if piece.team == white {
+                    fill = options.WhitePieceColor
+                }
+                drawPieceBitmap(img, rect, pieceBitmaps[piece.kind()], fill, options.OutlineColor)
+            }
+        }
+    }
+    return img
+}
+
+func (board Board) WritePNG(writer io.Writer, options ImageOptions, highlightedPositions []string) error {
+    return png.Encode(writer, board.Image(options, highlightedPositions))
+}
+
+// Replay the game of a playbook file and return the board after the last move and the last move ("e2 e4", "" if none).
+// If frame is not nil it is called with the board after the setup and after every move.
+func ReplayPlaybook(path string, frame func(board *Board, lastMove string)) (board *Board, lastMove string, err error) {
+    defer func() {
+        if recovered := recover(); recovered != nil {
+            err = fmt.Errorf("%v", recovered)
+        }
+    }()
+
+    testCase := utils.ParseTestCase(path)
+    board = NewBoard()
+    board.setup(testCase)
+    if frame != nil {
+        frame(board, "")
+    }
+
+    team := white
+    for i, move := range testCase.Moves {
+        if move == "" {
+            continue
+        }
+        if err := board.tryExecute(move, team); err != nil {
+            return nil, "", fmt.Errorf("move %d (%s): %v", i+1, move, err)
+        }
+        lastMove = move
+        if frame != nil {
+            frame(board, lastMove)
+        }
+        team = getOpponentTeam(team)
+    }
+    return board, lastMove, nil
+}
+
+// Replay the game of a playbook file as an animated GIF, one frame per position.
+// The final position is shown three times longer before the animation loops.
+func WritePlaybookGIF(writer io.Writer, path string, options ImageOptions, frameDelay time.Duration) error {
+    animation := &gif.GIF{}
+    palette := options.palette()
+    delay := int(frameDelay / (10 * time.Millisecond)) //GIF delays are in 100ths of a second
+
+    _, _, err := ReplayPlaybook(path, func(board *Board, lastMove string) {
+        img := board.Image(options, strings.Fields(lastMove))
+        frame := image.NewPaletted(img.Bounds(), palette)
+        draw.Draw(frame, frame.Bounds(), img, image.Point{}, draw.Src)
+        animation.Image = append(animation.Image, frame)
+        animation.Delay = append(animation.Delay, delay)
+    })
+    if err != nil {
+        return err
+    }
+    if len(animation.Image) == 0 {
+        return errors.New("no positions to draw")
+    }
+    animation.Delay[len(animation.Delay)-1] = delay * 3
+
+    return gif.EncodeAll(writer, animation)
+}
+
+// Execute the move and return the panic message of an illegal move as error
+func (board *Board) tryExecute(command string, team Team) (err error) {
+    defer func() {
+        if recovered := recover(); recovered != nil {
+            err = fmt.Errorf("%v", recovered)
+        }
+    }()
+    board.execute(command, team)
+    return nil
+}
+
+// Scale the bitmap into rect, filling the piece and outlining its edge
+func drawPieceBitmap(img *image.RGBA, rect image.Rectangle, bitmap []string, fill, outline color.RGBA) {
+    size := rect.Dx()
+    bitmapSize := len(bitmap)
+    isPiece := func(x, y int) bool {
+        if x < 0 || y < 0 || x >= size || y >= size {
+            return false
+        }
+        return bitmap[y*bitmapSize/size][x*bitmapSize/size] == 'X'
+    }
+
+    thickness := size / 32
+    if thickness < 1 {
+        thickness = 1
+    }
+
+    for y := 0; y < size; y++ {
+        for x := 0; x < size; x++ {
+            if !isPiece(x, y) {
+                continue
+            }
+            pixelColor := fill
+            for d := 1; d <= thickness; d++ {
+                if !isPiece(x-d, y) || !isPiece(x+d, y) || !isPiece(x, y-d) || !isPiece(x, y+d) {
+                    pixelColor = outline
+                    break
+                }
+            }
+            img.SetRGBA(rect.Min.X+x, rect.Min.Y+y, pixelColor)
+        }
+    }
+}
+
+// Piece silhouettes, 16x16 with 'X' for the piece
+var pieceBitmaps = map[string][]string{
+    "k": {
+        ".......XX.......",
+        "......XXXX......",
+        ".......XX.......",
+        "..XXX..XX..XXX..",
+        ".XXXXX.XX.XXXXX.",
+        ".XXXXXXXXXXXXXX.",
+        ".XXXXXXXXXXXXXX.",
+        "..XXXXXXXXXXXX..",
+        "...XXXXXXXXXX...",
+        "....XXXXXXXX....",
+        "....XXXXXXXX....",
+        ".....XXXXXX.....",
+        "....XXXXXXXX....",
+        "...XXXXXXXXXX...",
+        "..XXXXXXXXXXXX..",
+        "................",
+    },
+    "q": {
+        "................",
+        ".X....X..X....X.",
+        ".XX...XXXX...XX.",
+        ".XXX..XXXX..XXX.",
+        "..XXX.XXXX.XXX..",
+        "..XXXXXXXXXXXX..",
+        "...XXXXXXXXXX...",
+        "...XXXXXXXXXX...",
+        "....XXXXXXXX....",
+        "....XXXXXXXX....",
+        ".....XXXXXX.....",
+        "....XXXXXXXX....",
+        "...XXXXXXXXXX...",
+        "..XXXXXXXXXXXX..",
+        "..XXXXXXXXXXXX..",
+        "................",
+    },
+    "r": {
+        "................",
+        "................",
+        "...XX.XXXX.XX...",
+        "...XXXXXXXXXX...",
+        "...XXXXXXXXXX...",
+        "....XXXXXXXX....",
+        ".....XXXXXX.....",
+        ".....XXXXXX.....",
+        ".....XXXXXX.....",
+        ".....XXXXXX.....",
+        ".....XXXXXX.....",
+        "....XXXXXXXX....",
+        "...XXXXXXXXXX...",
+        "..XXXXXXXXXXXX..",
+        "..XXXXXXXXXXXX..",
+        "................",
+    },
+    "b": {
+        "................",
+        ".......XX.......",
+        "......XXXX......",
+        ".....XXXXXX.....",
+        ".....XXXX.X.....",
+        "....XXXX.XXX....",
+        "....XXXXXXXX....",
+        ".....XXXXXX.....",
+        "......XXXX......",
+        ".....XXXXXX.....",
+        "......XXXX......",
+        "......XXXX......",
+        "....XXXXXXXX....",
+        "..XXXXXXXXXXXX..",
+        "..XXXXXXXXXXXX..",
+        "................",
+    },
+    "n": {
+        "................",
+        "......X.X.......",
+        ".....XXXXX......",
+        "....XXXXXXX.....",
+        "...XXXXXXXXX....",
+        "..XXXX.XXXXXX...",
+        "..XXXXXXXXXXX...",
+        "...XX.XXXXXXX...",
+        ".....XXXXXXXX...",
+        "....XXXXXXXX....",
+        "....XXXXXXXX....",
+        ".....XXXXXX.....",
+        "....XXXXXXXX....",
+        "...XXXXXXXXXX...",
+        "...XXXXXXXXXX...",
+        "................",
+    },
+    "p": {
+        "................",
+        "................",
+        "................",
+        "......XXXX......",
+        ".....XXXXXX.....",
+        ".....XXXXXX.....",
+        "......XXXX......",
+        ".....XXXXXX.....",
+        "......XXXX......",
+        "......XXXX......",
+        ".....XXXXXX.....",
+        "....XXXXXXXX....",
+        "...XXXXXXXXXX...",
+        "...XXXXXXXXXX...",
+        "................",
+        "................",
+    },
+}
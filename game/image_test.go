@@ -0,0 +1,127 @@
+package game
+
+import (
+    "bytes"
+    "image/color"
+    "image/gif"
+    "image/png"
+    "io/ioutil"
+    "os"
+    "path/filepath"
+    "strings"
+    "testing"
+    "time"
+)
+
+// Write a playbook of the initial board followed by the moves, in the format of playbook/initialBoard.txt
+func writeTestPlaybook(t *testing.T, moves ...string) string {
+    initial, err := ioutil.ReadFile(filepath.Join("..", "playbook", "initialBoard.txt"))
+    if err != nil {
+        t.Fatal(err)
+    }
+    content := strings.TrimSpace(string(initial)) + "\n" + strings.Join(moves, "\n") + "\n"
+
+    dir, err := ioutil.TempDir("", "playbook")
+    if err != nil {
+        t.Fatal(err)
+    }
+    t.Cleanup(func() { os.RemoveAll(dir) })
+    path := filepath.Join(dir, "game.txt")
+    if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+        t.Fatal(err)
+    }
+    return path
+}
+
+func TestWritePNG(t *testing.T) {
+    options := DefaultImageOptions()
+    options.SquareSize = 16
+    var buffer bytes.Buffer
+    if err := NewBoard().WritePNG(&buffer, options, []string{"e4"}); err != nil {
+        t.Fatal(err)
+    }
+
+    img, err := png.Decode(&buffer)
+    if err != nil {
+        t.Fatal(err)
+    }
+    if bounds := img.Bounds(); bounds.Dx() != 128 || bounds.Dy() != 128 {
+        t.Fatalf("size %v, expected 128x128", bounds)
+    }
+
+    tests := []struct {
+        x, y     int
+        expected color.RGBA
+    }{
+        {4 * 16, 4 * 16, options.HighlightColor}, //e4
+        {3 * 16, 4 * 16, options.DarkColor},      //d4
+        {0, 4 * 16, options.LightColor},          //a4
+    }
+    for _, test := range tests {
+        if pixel := color.RGBAModel.Convert(img.At(test.x, test.y)); pixel != test.expected {
+            t.Errorf("pixel (%d, %d): %v, expected %v", test.x, test.y, pixel, test.expected)
+        }
+    }
+}
+
+func TestReplayPlaybook(t *testing.T) {
+    path := writeTestPlaybook(t, "e2 e4", "e7 e5", "g1 f3")
+    frames := 0
+    board, lastMove, err := ReplayPlaybook(path, func(board *Board, lastMove string) {
+        frames++
+    })
+    if err != nil {
+        t.Fatal(err)
+    }
+    if lastMove != "g1 f3" || frames != 4 {
+        t.Errorf("last move %q after %d frames", lastMove, frames)
+    }
+    if !board.getSquare("f3").hasPiece() || board.getSquare("g1").hasPiece() {
+        t.Error("the Knight did not move to f3")
+    }
+}
+
+func TestReplayPlaybookErrors(t *testing.T) {
+    if _, _, err := ReplayPlaybook(writeTestPlaybook(t, "e2 e4", "e2 e4"), nil); err == nil || !strings.HasPrefix(err.Error(), "move 2 (e2 e4): ") {
+        t.Errorf("illegal move: %v", err)
+    }
+    if _, _, err := ReplayPlaybook(writeTestPlaybook(t, "e2 e"), nil); err == nil || !strings.HasPrefix(err.Error(), "move 1 (e2 e): ") {
+        t.Errorf("malformed move: %v", err)
+    }
+    if _, _, err := ReplayPlaybook(filepath.Join(t.Name(), "missing.txt"), nil); err == nil {
+        t.Error("no error for a missing playbook")
+    }
+    if err := WritePlaybookGIF(&bytes.Buffer{}, writeTestPlaybook(t, "e7 e5"), DefaultImageOptions(), time.Second); err == nil {
+        t.Error("GIF written for a playbook with an illegal move")
+    }
+}
+
+func TestWritePlaybookGIF(t *testing.T) {
+    moves := []string{"e2 e4", "e7 e5", "g1 f3", "b8 c6"}
+    options := DefaultImageOptions()
+    options.SquareSize = 8
+    var buffer bytes.Buffer
+    if err := WritePlaybookGIF(&buffer, writeTestPlaybook(t, moves...), options, 500*time.Millisecond); err != nil {
+        t.Fatal(err)
+    }
+
+    animation, err := gif.DecodeAll(&buffer)
+    if err != nil {
+        t.Fatal(err)
+    }
+    if len(animation.Image) != len(moves)+1 {
+        t.Fatalf("%d frames, expected one per position: %d", len(animation.Image), len(moves)+1)
+    }
+    for i, frame := range animation.Image {
+        if bounds := frame.Bounds(); bounds.Dx() != 64 || bounds.Dy() != 64 {
+            t.Errorf("frame %d size %v, expected 64x64", i, bounds)
+        }
+        expected := 50 //in 100ths of a second
+        if i == len(animation.Image)-1 {
+            expected = 150 //the final position is shown three times longer
+        }
+        if animation.Delay[i] != expected {
+            t.Errorf("frame %d delay %d, expected %d", i, animation.Delay[i], expected)
+        }
+    }
+}
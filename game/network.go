@@ -0,0 +1,378 @@
+package game
+
+import (
+    "errors"
+    "fmt"
+    "net"
+    "os"
+    "strconv"
+    "strings"
+    "time"
+)
+
+// MARK: Network play (two terminals playing each other over TCP)
+//
+// Both ends run the same game and check every move with their own rules. The protocol is one message per line:
+//
+//   HELLO 1 <color of the guest> <base ms> <increment ms> <delay ms> <moves per session>   host to guest
+//   HELLO 1                                                                               guest to host
+//   MOVE e2 e4          CLOCK <ms>          TIMEOUT
+//   DRAW   ACCEPT   DECLINE   RESIGN   ABORT
+//   CHAT <text>
+//
+// MOVE, CLOCK, TIMEOUT, ACCEPT and DECLINE are only valid on the turn of the sender. CHAT, DRAW, RESIGN and ABORT
+// are valid at any time, a DRAW crossing the opponent's DRAW is an agreed draw.
+// CLOCK is sent before MOVE with the time the mover has left, each player keeps their own clock,
+// and TIMEOUT instead of MOVE when the mover's flag fell. The opponent's clock runs on the other end for display
+// and is set to the time of their CLOCK message.
+// A closed connection ends the game, the player still connected wins by abandonment.
+
+const (
+    protocolVersion    = "1"
+    chatCommand        = "chat"
+    timeoutMessage     = "TIMEOUT"
+    networkGracePeriod = 5 * time.Second //extra time allowed for the opponent's messages to arrive
+)
+
+type peer struct {
+    conn     net.Conn
+    messages <-chan string
+}
+
+func newPeer(conn net.Conn) *peer {
+    return &peer{conn, readLines(conn)}
+}
+
+// Send a message, write errors show up as a closed connection on the reading side
+func (peer *peer) send(words ...string) {
+    fmt.Fprintln(peer.conn, strings.Join(words, " "))
+}
+
+// Wait for the next message, false if the connection is closed or nothing arrives in time
+func (peer *peer) receive(timeout time.Duration) (string, bool) {
+    select {
+    case message, ok := <-peer.messages:
+        return strings.TrimSpace(message), ok
+    case <-time.After(timeout):
+        return "", false
+    }
+}
+
+// Play a network game as host, the host decides the colors and the time control set on the game
+func (game *ChessGame) HostNetworkGame(conn net.Conn, hostTeam Team) (Outcome, error) {
+    game.peer = newPeer(conn)
+    game.localTeam = hostTeam
+
+    var control TimeControl
+    if game.clocks != nil {
+        control = game.clocks[white].control
+    }
+    game.peer.send("HELLO", protocolVersion, getOpponentTeam(hostTeam).String(),
+        strconv.FormatInt(control.Base.Milliseconds(), 10), strconv.FormatInt(control.Increment.Milliseconds(), 10),
+        strconv.FormatInt(control.Delay.Milliseconds(), 10), strconv.Itoa(control.MovesPerSession))
+
+    reply, ok := game.peer.receive(time.Minute)
+    if !ok || reply != "HELLO "+protocolVersion {
+        conn.Close()
+        return Outcome{}, errors.New("opponent did not answer the handshake")
+    }
+    return game.playNetworkGame(), nil
+}
+
+// Play a network game hosted by the other end of the connection
+func (game *ChessGame) JoinNetworkGame(conn net.Conn) (Outcome, error) {
+    game.peer = newPeer(conn)
+
+    hello, ok := game.peer.receive(time.Minute)
+    fields := strings.Fields(hello)
+    if !ok || len(fields) != 7 || fields[0] != "HELLO" {
+        conn.Close()
+        return Outcome{}, errors.New("host did not send the handshake")
+    }
+    if fields[1] != protocolVersion {
+        conn.Close()
+        return Outcome{}, errors.New("host uses protocol version " + fields[1] + ", expected " + protocolVersion)
+    }
+    team, err := ParseTeam(fields[2])
+    if err != nil {
+        conn.Close()
+        return Outcome{}, err
+    }
+    game.localTeam = team
+
+    var values [4]int
+    for i := range values {
+        if values[i], err = strconv.Atoi(fields[i+3]); err != nil || values[i] < 0 {
+            conn.Close()
+            return Outcome{}, errors.New("invalid time control in handshake: " + hello)
+        }
+    }
+    if values[0] > 0 {
+        game.SetTimeControl(TimeControl{
+            Base:            time.Duration(values[0]) * time.Millisecond,
+            Increment:       time.Duration(values[1]) * time.Millisecond,
+            Delay:           time.Duration(values[2]) * time.Millisecond,
+            MovesPerSession: values[3],
+        })
+    }
+
+    game.peer.send("HELLO", protocolVersion)
+    return game.playNetworkGame(), nil
+}
+
+// Same loop as the interactive mode, the opponent's input comes from the connection
+func (game *ChessGame) playNetworkGame() Outcome {
+    defer game.peer.conn.Close()
+
+    game.setupBoard(InitialBoardFileName)
+    game.inputs = readLines(os.Stdin)
+    game.flipped = game.localTeam == black
+    fmt.Println("You play", getTeamName(game.localTeam)+". Type \"" + chatCommand + " <message>\" to talk to your opponent at any time.")
+    if game.clocks != nil {
+        fmt.Println("Time control:", game.clocks[white].control)
+    }
+    game.printGameStatus()
+
+    for {
+        game.changeTurn(true)
+        if game.curTeam == game.localTeam {
+            game.printAvailableMovesInCheck()
+            game.printDrawOffer()
+        }
+        game.startClock()
+        input, inTime := game.promptNetworkInput()
+        if game.outcome != nil {
+            return *game.outcome //connection lost or aborted while waiting
+        }
+        if !inTime {
+            if game.curTeam == game.localTeam {
+                game.peer.send(timeoutMessage)
+            }
+            game.endGameOnTime()
+            return *game.outcome
+        }
+        gameEnd := game.execute(input)
+        if gameEnd {
+            return *game.outcome
+        }
+    }
+}
+
+// Wait for the input of the current player, from the terminal or from the connection.
+// Chat messages are passed on while waiting. Return false if the current player's flag falls first.
+func (game *ChessGame) promptNetworkInput() (string, bool) {
+    local := game.curTeam == game.localTeam
+    var ticks <-chan time.Time //nil channel never fires when playing without clocks
+    if local {
+        fmt.Print(getTeamName(game.curTeam), "> ")
+    } else if game.clocks != nil {
+        //Count the opponent's time down on the waiting line
+        ticker := time.NewTicker(time.Second)
+        defer ticker.Stop()
+        defer fmt.Println()
+        ticks = ticker.C
+        game.printWaiting()
+    } else {
+        game.printWaiting()
+    }
+
+    var timeout <-chan time.Time
+    if clock := game.clocks[game.curTeam]; clock != nil {
+        remaining := clock.Remaining(time.Now())
+        if !local {
+            remaining += networkGracePeriod
+        }
+        timer := time.NewTimer(remaining)
+        defer timer.Stop()
+        timeout = timer.C
+    }
+
+    for {
+        select {
+        case input, ok := <-game.inputs:
+            if !ok {
+                input = abortCommand //no more input
+            }
+            input = strings.TrimSpace(input)
+            if tokens := strings.SplitN(input, " ", 2); tokens[0] == chatCommand {
+                if len(tokens) == 2 {
+                    game.peer.send("CHAT", tokens[1])
+                }
+            } else if local {
+                return input, true
+            } else if input == resignCommand || input == drawCommand || input == abortCommand {
+                game.peer.send(strings.ToUpper(input))
+                if game.executeOffTurn(input, game.localTeam) {
+                    return "", true
+                }
+            } else {
+                fmt.Println("Please wait for your opponent's move.")
+            }
+            if local {
+                fmt.Print(getTeamName(game.curTeam), "> ")
+            }
+
+        case message, ok := <-game.peer.messages:
+            if !ok {
+                fmt.Println()
+                fmt.Println("Connection to the opponent was lost.")
+                game.endGame(newWin(game.localTeam, Abandoned), "")
+                return "", true
+            }
+            command, err := game.parsePeerMessage(strings.TrimSpace(message))
+            if err != nil {
+                fmt.Println()
+                fmt.Println("Opponent sent an invalid message:", err)
+                game.peer.send("ABORT")
+                game.endGame(Outcome{undecided, Aborted}, "")
+                return "", true
+            }
+            switch command {
+            case "": //handled already, keep waiting
+                if local {
+                    fmt.Print(getTeamName(game.curTeam), "> ")
+                }
+            case timeoutMessage:
+                return "", false
+            case resignCommand, drawCommand, abortCommand:
+                if !local {
+                    return command, true
+                }
+                fmt.Println()
+                if game.executeOffTurn(command, game.getRemoteTeam()) {
+                    return "", true
+                }
+                fmt.Print(getTeamName(game.curTeam), "> ")
+            default:
+                return command, true
+            }
+
+        case <-ticks:
+            game.printWaiting()
+
+        case <-timeout:
+            fmt.Println()
+            return "", false
+        }
+    }
+}
+
+// Show whose turn it is while waiting for the opponent, with their time left when playing with clocks
+func (game ChessGame) printWaiting() {
+    clock := game.clocks[game.curTeam]
+    if clock == nil {
+        fmt.Println("Waiting for", getTeamName(game.curTeam)+"...")
+        return
+    }
+    fmt.Print("\rWaiting for ", getTeamName(game.curTeam), "... ", clock, " ")
+}
+
+// Resign, offer a draw or abort while the other player is on turn, return true if the game ended
+func (game *ChessGame) executeOffTurn(command string, team Team) bool {
+    fmt.Println(getTeamName(team), " player action: ", command)
+    opponent := getOpponentTeam(team)
+
+    switch command {
+    case resignCommand:
+        game.endGame(newWin(opponent, Resignation), "")
+        return true
+
+    case drawCommand:
+        if game.drawOfferedBy == opponent {
+            game.endGame(newDraw(Agreement), "") //both players want a draw
+            return true
+        }
+        game.drawOfferedBy = team
+        if team == game.localTeam {
+            fmt.Println(getTeamName(team), "offers a draw.")
+        } else {
+            game.printDrawOffer()
+        }
+        return false
+
+    case abortCommand:
+        game.endGame(Outcome{undecided, Aborted}, "")
+        return true
+    }
+    return false
+}
+
+// Check a message from the opponent and turn it into the command to execute, "" if there is nothing to execute
+func (game *ChessGame) parsePeerMessage(message string) (string, error) {
+    tokens := strings.SplitN(message, " ", 2)
+    switch tokens[0] {
+    case "CHAT":
+        if len(tokens) == 2 {
+            fmt.Println()
+            fmt.Println(getTeamName(game.getRemoteTeam()), "says:", tokens[1])
+        }
+        return "", nil
+    case "ABORT":
+        return abortCommand, nil
+    case "DRAW":
+        return drawCommand, nil
+    case "RESIGN":
+        return resignCommand, nil
+    }
+
+    if game.curTeam == game.localTeam {
+        return "", errors.New("message out of turn: " + message)
+    }
+
+    switch tokens[0] {
+    case "CLOCK":
+        remaining, err := strconv.Atoi(strings.TrimSpace(tokens[len(tokens)-1]))
+        if len(tokens) != 2 || err != nil || remaining < 0 {
+            return "", errors.New("invalid clock: " + message)
+        }
+        if clock := game.clocks[game.curTeam]; clock != nil {
+            clock.pause(time.Now())
+            clock.remaining = time.Duration(remaining) * time.Millisecond
+        }
+        return "", nil
+    case "MOVE":
+        move := strings.Join(strings.Fields(message)[1:], " ")
+        if !containsMove(game.board.getLegalMoves(game.curTeam), move) {
+            return "", errors.New("illegal move: " + message)
+        }
+        return move, nil
+    case timeoutMessage:
+        return timeoutMessage, nil
+    case "ACCEPT", "DECLINE":
+        if game.drawOfferedBy != game.localTeam {
+            return "", errors.New("no draw offer to answer: " + message)
+        }
+        return strings.ToLower(tokens[0]), nil
+    }
+    return "", errors.New("unknown message: " + message)
+}
+
+// Tell the opponent about a command of the local player that was executed
+func (game ChessGame) sendToPeer(command string) {
+    if game.peer == nil || game.curTeam != game.localTeam {
+        return
+    }
+
+    switch command {
+    case resignCommand, drawCommand, acceptCommand, declineCommand, abortCommand:
+        game.peer.send(strings.ToUpper(command))
+        return
+    }
+
+    if clock := game.clocks[game.curTeam]; clock != nil {
+        if clock.remaining <= 0 {
+            game.peer.send(timeoutMessage) //flag fell before the move
+            return
+        }
+        game.peer.send("CLOCK", strconv.FormatInt(clock.remaining.Milliseconds(), 10))
+    }
+    game.peer.send("MOVE", command)
+}
+
+func (game ChessGame) isRemote(team Team) bool {
+    return game.peer != nil && team != game.localTeam
+}
+
+func (game ChessGame) getRemoteTeam() Team {
+    return getOpponentTeam(game.localTeam)
+}
@@ -0,0 +1,64 @@
+package game
+
+import (
+    "strings"
+    "testing"
+)
+
+// Network game with White to move, without a connection
+func newNetworkTestGame(t *testing.T, localTeam Team) ChessGame {
+    game := newTestGame(t, StartFEN)
+    game.localTeam = localTeam
+    game.changeTurn(true) //White to move
+    return game
+}
+
+func TestPeerMessagesOffTurn(t *testing.T) {
+    tests := []struct {
+        message string
+        command string
+        valid   bool
+    }{
+        {"CHAT hello", "", true},
+        {"DRAW", drawCommand, true},
+        {"RESIGN", resignCommand, true},
+        {"ABORT", abortCommand, true},
+        {"MOVE e7 e5", "", false},
+        {"CLOCK 1000", "", false},
+        {"TIMEOUT", "", false},
+        {"ACCEPT", "", false},
+    }
+
+    for _, test := range tests {
+        game := newNetworkTestGame(t, white) //Black sends while it's White's turn
+        command, err := game.parsePeerMessage(test.message)
+        if (err == nil) != test.valid || command != test.command {
+            t.Errorf("%s: got %q, %v", test.message, command, err)
+        }
+    }
+}
+
+func TestExecuteOffTurn(t *testing.T) {
+    game := newNetworkTestGame(t, white)
+    if game.executeOffTurn(drawCommand, black) || game.drawOfferedBy != black {
+        t.Fatal("off-turn draw offer must stay pending")
+    }
+    if game.executeGameCommand(acceptCommand); game.outcome == nil || *game.outcome != newDraw(Agreement) {
+        t.Errorf("accepting the off-turn offer: %v", game.outcome)
+    }
+
+    //Offers crossing each other
+    game = newNetworkTestGame(t, white)
+    game.drawOfferedBy = white
+    if !game.executeOffTurn(drawCommand, black) || *game.outcome != newDraw(Agreement) {
+        t.Errorf("crossing draw offers must agree a draw: %v", game.outcome)
+    }
+
+    game = newNetworkTestGame(t, black) //Black resigns while White is thinking
+    if !game.executeOffTurn(resignCommand, black) || *game.outcome != newWin(white, Resignation) {
+        t.Errorf("off-turn resignation: %v", game.outcome)
+    }
+    if pgn := game.PGN(); !strings.Contains(pgn, `[Result "1-0"]`) {
+        t.Errorf("resignation missing from the PGN\n%s", pgn)
+    }
+}
@@ -0,0 +1,77 @@
+package game
+
+import (
+    "strings"
+)
+
+// Get the Standard Algebraic Notation ("Nf3", "exd5", "Rad1") of a legal move before it is made.
+// Check and checkmate suffixes are added by addCheckSuffix() once the move is on the board.
+func (board *Board) toSAN(move Move, team Team) string {
+    if move.squareFrom == nil {
+        return strings.ToUpper(move.piece.kind()) + dropSeparator + getSquarePosition(*move.squareTo) //drop
+    }
+    if move.rook != nil {
+        return getCastlingSAN(move)
+    }
+
+    positionFrom := getSquarePosition(*move.squareFrom)
+    positionTo := getSquarePosition(*move.squareTo)
+    capture := move.squareTo.hasPiece()
+
+    if move.piece.kind() == "p" {
+        if capture {
+            return positionFrom[:1] + "x" + positionTo
+        }
+        return positionTo
+    }
+
+    san := strings.ToUpper(move.piece.kind()) + board.getDisambiguation(move, team)
+    if capture {
+        san += "x"
+    }
+    return san + positionTo
+}
+
+// Get the file, rank or both of the origin when another piece of the same kind can reach the same square
+func (board *Board) getDisambiguation(move Move, team Team) string {
+    positionFrom := getSquarePosition(*move.squareFrom)
+    positionTo := getSquarePosition(*move.squareTo)
+
+    ambiguous, sameFile, sameRank := false, false, false
+    for _, legalMove := range board.getLegalMoves(team) {
+        tokens := strings.Split(legalMove, " ")
+        if tokens[1] != positionTo || tokens[0] == positionFrom {
+            continue
+        }
+        other := board.getSquare(tokens[0]).getPiece()
+        if other.kind() != move.piece.kind() {
+            continue
+        }
+        ambiguous = true
+        sameFile = sameFile || tokens[0][0] == positionFrom[0]
+        sameRank = sameRank || tokens[0][1] == positionFrom[1]
+    }
+
+    switch {
+    case !ambiguous:
+        return ""
+    case !sameFile:
+        return positionFrom[:1]
+    case !sameRank:
+        return positionFrom[1:]
+    default:
+        return positionFrom
+    }
+}
+
+// Add "+" or "#" to the SAN of the move just made by team
+func (board *Board) addCheckSuffix(san string, team Team) string {
+    opponent := getOpponentTeam(team)
+    if !board.inCheck(opponent) {
+        return san
+    }
+    if len(board.getAvailableMoves(opponent)) == 0 {
+        return san + "#"
+    }
+    return san + "+"
+}
@@ -0,0 +1,45 @@
+package game
+
+import (
+    "testing"
+)
+
+func TestSAN(t *testing.T) {
+    tests := []struct {
+        name     string
+        fen      string
+        commands []string
+        san      string //SAN of the last command
+    }{
+        {"Pawn move", StartFEN, []string{"e2 e4"}, "e4"},
+        {"Knight move", StartFEN, []string{"g1 f3"}, "Nf3"},
+        {"Pawn capture", StartFEN, []string{"e2 e4", "d7 d5", "e4 d5"}, "exd5"},
+        {"piece capture", StartFEN, []string{"e2 e4", "e7 e5", "g1 f3", "b8 c6", "f3 e5"}, "Nxe5"},
+        {"file disambiguation", "4k3/8/8/8/8/5N2/8/1N2K3 w - - 0 1", []string{"b1 d2"}, "Nbd2"},
+        {"file disambiguation with a capture", "4k3/8/8/8/8/5N2/3p4/1N2K3 w - - 0 1", []string{"b1 d2"}, "Nbxd2"},
+        {"rank disambiguation", "4k3/8/R7/8/8/8/8/R3K3 w - - 0 1", []string{"a1 a3"}, "R1a3"},
+        {"rank disambiguation from above", "4k3/8/R7/8/8/8/8/R3K3 w - - 0 1", []string{"a6 a3"}, "R6a3"},
+        {"full square disambiguation", "4k3/8/8/8/8/Q7/8/Q1Q1K3 w - - 0 1", []string{"a1 b2"}, "Qa1b2"},
+        {"no disambiguation for a pinned piece", "4k3/8/8/8/8/5N2/8/rN2K3 w - - 0 1", []string{"f3 d2"}, "Nd2"},
+        {"other kinds don't need disambiguation", "4k3/8/8/8/8/5B2/8/1N2K3 w - - 0 1", []string{"b1 d2"}, "Nd2"},
+        {"check", StartFEN, []string{"e2 e4", "f7 f6", "d1 h5"}, "Qh5+"},
+        {"fool's mate", StartFEN, []string{"f2 f3", "e7 e5", "g2 g4", "d8 h4"}, "Qh4#"},
+        {"capture with check", "4k3/8/8/8/8/8/4p3/R3K3 b - - 0 1", []string{"e8 d7", "a1 a7"}, "Ra7+"},
+        {"castling with check", "5k2/8/8/8/8/8/8/4K2R w K - 0 1", []string{"e1 g1"}, "O-O+"},
+        {"drop", "4k3/8/8/8/8/8/8/4K3[N] w - - 0 1", []string{"N@f6"}, "N@f6+"},
+    }
+
+    for _, test := range tests {
+        game := newTestGame(t, test.fen)
+        var san string
+        for _, command := range test.commands {
+            var err error
+            if san, err = game.Move(command); err != nil {
+                t.Fatalf("%s: %s %v", test.name, command, err)
+            }
+        }
+        if san != test.san {
+            t.Errorf("%s: %s, expected %s", test.name, san, test.san)
+        }
+    }
+}
@@ -0,0 +1,135 @@
+package game
+
+// Termination reason of a game
+type Termination int
+const (
+    Checkmate            Termination = iota
+    Stalemate            Termination = iota
+    Resignation          Termination = iota
+    Timeout              Termination = iota
+    Repetition           Termination = iota
+    FiftyMove            Termination = iota
+    InsufficientMaterial Termination = iota
+    Agreement            Termination = iota
+    MoveLimit            Termination = iota
+    Aborted              Termination = iota
+    Abandoned            Termination = iota //opponent disconnected during a network game
+)
+
+func (reason Termination) String() string {
+    switch reason {
+    case Checkmate:
+        return "checkmate"
+    case Stalemate:
+        return "stalemate"
+    case Resignation:
+        return "resignation"
+    case Timeout:
+        return "time forfeit"
+    case Repetition:
+        return "threefold repetition"
+    case FiftyMove:
+        return "fifty-move rule"
+    case InsufficientMaterial:
+        return "insufficient material"
+    case Agreement:
+        return "agreement"
+    case MoveLimit:
+        return "move limit"
+    case Aborted:
+        return "abort"
+    case Abandoned:
+        return "abandonment"
+
+    default:
+        return "unknown"
+    }
+}
+
+// Get the value of the PGN Termination tag, which only tells how the game ended in general.
+// The detailed reason is in Outcome.pgnComment().
+func (reason Termination) pgnTermination() string {
+    switch reason {
+    case Timeout:
+        return "time forfeit"
+    case MoveLimit:
+        return "adjudication"
+    case Aborted:
+        return "unterminated"
+    case Abandoned:
+        return "abandoned"
+
+    default:
+        return "normal"
+    }
+}
+
+// MARK: Outcome (how a finished game ended)
+type Outcome struct {
+    winner Team //undecided for draws and aborted games
+    Reason Termination
+}
+
+func newWin(winner Team, reason Termination) Outcome {
+    return Outcome{winner, reason}
+}
+
+func newDraw(reason Termination) Outcome {
+    return Outcome{undecided, reason}
+}
+
+// Get the winning team, undecided for draws and aborted games
+func (outcome Outcome) Winner() Team {
+    return outcome.winner
+}
+
+func (outcome Outcome) IsDraw() bool {
+    return outcome.winner == undecided && outcome.Reason != Aborted
+}
+
+func (outcome Outcome) WhiteWins() bool {
+    return outcome.winner == white
+}
+
+func (outcome Outcome) BlackWins() bool {
+    return outcome.winner == black
+}
+
+// Game result as written in PGN: "1-0", "0-1", "1/2-1/2" or "*" for aborted games
+func (outcome Outcome) Result() string {
+    switch {
+    case outcome.WhiteWins():
+        return "1-0"
+    case outcome.BlackWins():
+        return "0-1"
+    case outcome.IsDraw():
+        return "1/2-1/2"
+    default:
+        return "*"
+    }
+}
+
+func (outcome Outcome) String() string {
+    switch {
+    case outcome.Reason == Aborted:
+        return "Game aborted."
+    case outcome.IsDraw():
+        return "Tie game by " + outcome.Reason.String() + "."
+    default:
+        return getTeamName(outcome.winner) + " wins by " + outcome.Reason.String() + "."
+    }
+}
+
+// Get how the game ended for the comment before the result in PGN movetext, e.g. "White wins by resignation."
+func (outcome Outcome) pgnComment() string {
+    switch {
+    case outcome.Reason == Aborted:
+        return "Game aborted."
+    case outcome.IsDraw():
+        return "Draw by " + outcome.Reason.String() + "."
+    case outcome.WhiteWins():
+        return "White wins by " + outcome.Reason.String() + "."
+    default:
+        return "Black wins by " + outcome.Reason.String() + "."
+    }
+}
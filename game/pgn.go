@@ -0,0 +1,69 @@
+package game
+
+import (
+    "strings"
+    "time"
+)
+
+const pgnLineLength = 80
+
+// Get the game in PGN. Player names are not known, so they are "?".
+func (game ChessGame) PGN() string {
+    result := "*"
+    if game.outcome != nil {
+        result = game.outcome.Result()
+    }
+
+    tags := [][2]string{
+        {"Event", "Casual game"},
+        {"Site", "?"},
+        {"Date", time.Now().Format("2006.01.02")},
+        {"Round", "-"},
+        {"White", "?"},
+        {"Black", "?"},
+        {"Result", result},
+    }
+    if game.outcome != nil {
+        tags = append(tags, [2]string{"Termination", game.outcome.Reason.pgnTermination()})
+    }
+    if game.variant != "" {
+        tags = append(tags, [2]string{"Variant", game.variant})
+    }
+    if game.startFEN != "" {
+        tags = append(tags, [2]string{"SetUp", "1"}, [2]string{"FEN", game.startFEN})
+    }
+    if opening, found := game.Opening(); found {
+        tags = append(tags, [2]string{"ECO", opening.ECO}, [2]string{"Opening", opening.Name})
+    }
+
+    var builder strings.Builder
+    for _, tag := range tags {
+        value := strings.ReplaceAll(strings.ReplaceAll(tag[1], `\`, `\\`), `"`, `\"`)
+        builder.WriteString("[" + tag[0] + ` "` + value + "\"]\n")
+    }
+    builder.WriteString("\n")
+
+    //Move text, wrapped so that no line is longer than pgnLineLength
+    tokens := game.history.numberedTokens(game.startMoveNumber, game.blackStarts)
+    if game.outcome != nil {
+        tokens = append(tokens, "{"+game.outcome.pgnComment()+"}")
+    }
+    tokens = append(tokens, result)
+
+    lineLength := 0
+    for i, token := range tokens {
+        if i > 0 {
+            if lineLength+1+len(token) > pgnLineLength {
+                builder.WriteString("\n")
+                lineLength = 0
+            } else {
+                builder.WriteString(" ")
+                lineLength++
+            }
+        }
+        builder.WriteString(token)
+        lineLength += len(token)
+    }
+    builder.WriteString("\n")
+    return builder.String()
+}
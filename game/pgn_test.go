@@ -0,0 +1,79 @@
+package game
+
+import (
+    "strings"
+    "testing"
+)
+
+// Enter the commands at the prompt, one turn each like the interactive loop
+func playCommands(game *ChessGame, commands ...string) {
+    for _, command := range commands {
+        game.changeTurn(true)
+        if game.execute(command) {
+            return
+        }
+    }
+}
+
+func TestPGNRecordsHowTheGameEnded(t *testing.T) {
+    tests := []struct {
+        name        string
+        commands    []string
+        result      string
+        termination string
+        comment     string //the detailed reason before the result
+    }{
+        {"resign", []string{"e2 e4", "resign"}, "1-0", "normal", "{White wins by resignation.}"},
+        {"agreed draw", []string{"draw", "e2 e4", "accept"}, "1/2-1/2", "normal", "{Draw by agreement.}"},
+        {"abort", []string{"e2 e4", "abort"}, "*", "unterminated", "{Game aborted.}"},
+        {"checkmate", []string{"f2 f3", "e7 e5", "g2 g4", "d8 h4"}, "0-1", "normal", "{Black wins by checkmate.}"},
+    }
+
+    for _, test := range tests {
+        game := newTestGame(t, StartFEN)
+        playCommands(&game, test.commands...)
+        if game.outcome == nil {
+            t.Fatalf("%s: game did not end", test.name)
+        }
+
+        pgn := game.PGN()
+        for _, tag := range []string{`[Result "` + test.result + `"]`, `[Termination "` + test.termination + `"]`} {
+            if !strings.Contains(pgn, tag+"\n") {
+                t.Errorf("%s: missing %s in\n%s", test.name, tag, pgn)
+            }
+        }
+        if !strings.HasSuffix(strings.TrimSpace(pgn), " "+test.comment+" "+test.result) {
+            t.Errorf("%s: movetext must end with %s %s\n%s", test.name, test.comment, test.result, pgn)
+        }
+    }
+}
+
+func TestPGNTermination(t *testing.T) {
+    tests := map[Termination]string{
+        Checkmate:            "normal",
+        Stalemate:            "normal",
+        Resignation:          "normal",
+        Timeout:              "time forfeit",
+        Repetition:           "normal",
+        FiftyMove:            "normal",
+        InsufficientMaterial: "normal",
+        Agreement:            "normal",
+        MoveLimit:            "adjudication",
+        Aborted:              "unterminated",
+        Abandoned:            "abandoned",
+    }
+    for reason, expected := range tests {
+        if termination := reason.pgnTermination(); termination != expected {
+            t.Errorf("%s: %s, expected %s", reason, termination, expected)
+        }
+    }
+}
+
+func TestPGNWithoutOutcome(t *testing.T) {
+    game := newTestGame(t, StartFEN)
+    playCommands(&game, "e2 e4")
+    pgn := game.PGN()
+    if !strings.Contains(pgn, `[Result "*"]`) || strings.Contains(pgn, "[Termination") || strings.Contains(pgn, "{") {
+        t.Errorf("game in progress must have result * and no termination\n%s", pgn)
+    }
+}
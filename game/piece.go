@@ -71,6 +71,14 @@ func getPieceSymbol(sign string) string {
     }
 }
 
+// Get the ASCII letter of the piece in the usual notation: upper case for White and lower case for Black
+func getPieceLetter(sign string) string {
+    if sign == strings.ToUpper(sign) {
+        return strings.ToLower(sign)
+    }
+    return strings.ToUpper(sign)
+}
+
 // MARK: Functions about Piece's movement
 func getMoves(board Board, piece Piece) []string {
 
@@ -294,23 +302,24 @@ func getPawnMoves(row, col int, board Board, team Team) []string {
 
     //Get one step forwards positions
     position := getCoordinatePosition(oneStepRow, col)
-    if board.isEmptyAt(position) {
+    oneStepFree := board.isEmptyAt(position)
+    if oneStepFree {
         moves = append(moves, position)
     }
 
-    //Get two step forwards positions if it's first move
+    //Get two step forwards positions if it's first move (cannot jump over the piece in front)
     position = getCoordinatePosition(twoStepsRow, col)
-    if firstMove && board.isEmptyAt(position) {
+    if firstMove && oneStepFree && board.isEmptyAt(position) {
         moves = append(moves, position)
     }
 
     //Get Two Killing positions if there is enemy nearby to kill
     position = getCoordinatePosition(oneStepRow, col-1)
-    if board.canMoveTo(position, team) {
+    if board.hasOpponentAt(position, team) {
         moves = append(moves, position)
     }
     position = getCoordinatePosition(oneStepRow, col+1)
-    if board.canMoveTo(position, team) {
+    if board.hasOpponentAt(position, team) {
         moves = append(moves, position)
     }
 
@@ -0,0 +1,207 @@
+package game
+
+import (
+    "errors"
+    "fmt"
+    "strconv"
+    "strings"
+    "time"
+)
+
+// MARK: Non-interactive play (games driven by a program instead of the terminal, e.g. the HTTP server)
+// Between calls curTeam is the team that made the last move, undecided before the first move of a new game.
+// Nothing is printed, errors are returned instead.
+
+// Create a game from a FEN string. Castling rights can be standard, Shredder-FEN or X-FEN, rights that need Chess960
+// castling make it a Chess960 game. The en passant field is ignored, the rules don't have it yet.
+// Pockets in brackets after the piece placement ("...RNBQKBNR[Nn]") make it a Crazyhouse game.
+func NewFromFEN(fen string) (ChessGame, error) {
+    board, err := NewBoardFromFEN(fen)
+    if err != nil {
+        return ChessGame{}, err
+    }
+    game := ChessGame{board: board, curTeam: undecided, fromFEN: true}
+
+    fields := strings.Fields(fen)
+    if len(fields) > 1 {
+        switch fields[1] {
+        case "w":
+        case "b":
+            game.curTeam = white //as if WHITE Player just moved
+            game.blackStarts = true
+        default:
+            return ChessGame{}, errors.New("FEN side to move must be w or b: " + fields[1])
+        }
+    }
+    if len(fields) > 2 {
+        if err := game.board.setCastlingField(fields[2]); err != nil {
+            return ChessGame{}, err
+        }
+    }
+    if len(fields) > 4 {
+        if game.startHalfMoves, err = strconv.Atoi(fields[4]); err != nil || game.startHalfMoves < 0 {
+            return ChessGame{}, errors.New("invalid FEN halfmove clock: " + fields[4])
+        }
+    }
+    if len(fields) > 5 {
+        if game.startMoveNumber, err = strconv.Atoi(fields[5]); err != nil || game.startMoveNumber < 1 {
+            return ChessGame{}, errors.New("invalid FEN move number: " + fields[5])
+        }
+    }
+
+    if game.board.inCheck(getOpponentTeam(game.SideToMove())) {
+        return ChessGame{}, errors.New("the side not to move is in check")
+    }
+    if game.board.dropsAllowed {
+        game.variant = crazyhouseVariant
+    } else if game.board.chess960 {
+        game.variant = chess960Variant
+    }
+    if fen := game.FEN(); fen != StartFEN && fen != CrazyhouseStartFEN {
+        game.startFEN = fen
+    }
+    return game, nil
+}
+
+// Get the team that makes the next move
+func (game ChessGame) SideToMove() Team {
+    if game.curTeam == white {
+        return black
+    }
+    return white
+}
+
+// Start the clock of the side to move. Move() starts the clocks after each move by itself.
+func (game ChessGame) StartClocks() {
+    if clock := game.clocks[game.SideToMove()]; clock != nil {
+        clock.Start(time.Now())
+    }
+}
+
+// Make the move ("e2 e4", or a castling like "e1 g1") for the side to move and return it in SAN
+func (game *ChessGame) Move(command string) (san string, err error) {
+    if game.Outcome() != nil {
+        return "", errors.New(gameOverMessage)
+    }
+
+    game.changeTurn(true)
+    defer func() {
+        if recovered := recover(); recovered != nil {
+            game.changeTurn(false)
+            err = fmt.Errorf("%v", recovered)
+        }
+    }()
+
+    outcome := game.makeMove(strings.TrimSpace(command))
+    san = game.history[len(game.history)-1]
+
+    if game.stopClock() {
+        outcome := game.getTimeoutOutcome(game.curTeam)
+        game.outcome = &outcome
+        return san, nil
+    }
+    if outcome != nil {
+        game.outcome = outcome
+        return san, nil
+    }
+    game.StartClocks()
+    return san, nil
+}
+
+// Take back the last move. The increment, delay and session time the move earned are taken off the mover's clock,
+// but time used on the clocks is not given back.
+func (game *ChessGame) Undo() error {
+    if game.Outcome() != nil {
+        return errors.New(gameOverMessage)
+    }
+    if !game.board.undoLastMove() {
+        return errors.New(noMoveToUndoMessage)
+    }
+
+    if clock := game.clocks[game.SideToMove()]; clock != nil {
+        clock.pause(time.Now())
+    }
+    if clock := game.clocks[game.curTeam]; clock != nil {
+        clock.takeBack()
+    }
+    game.history = game.history[:len(game.history)-1]
+    game.openings = game.openings[:len(game.openings)-1]
+    game.drawOfferedBy = undecided
+    game.changeTurn(false)
+    game.StartClocks()
+    return nil
+}
+
+func (game *ChessGame) Resign(team Team) error {
+    if team != white && team != black {
+        return errors.New("unknown side")
+    }
+    if game.Outcome() != nil {
+        return errors.New(gameOverMessage)
+    }
+    outcome := newWin(getOpponentTeam(team), Resignation)
+    game.outcome = &outcome
+    return nil
+}
+
+// Get how the game ended, nil while it's in progress. Ends the game if the flag of the side to move has fallen.
+func (game *ChessGame) Outcome() *Outcome {
+    if game.outcome == nil {
+        team := game.SideToMove()
+        if clock := game.clocks[team]; clock != nil && clock.Remaining(time.Now()) <= 0 {
+            clock.Stop(time.Now())
+            outcome := game.getTimeoutOutcome(team)
+            game.outcome = &outcome
+        }
+    }
+    return game.outcome
+}
+
+// Get the legal moves of the side to move ("e2 e4", and "N@f3" drops in crazyhouse), none when the game is over
+func (game ChessGame) LegalMoves() []string {
+    if game.outcome != nil {
+        return nil
+    }
+    return game.board.getAvailableMoves(game.SideToMove())
+}
+
+func (game ChessGame) InCheck() bool {
+    return game.board.inCheck(game.SideToMove())
+}
+
+// Get the moves played in SAN
+func (game ChessGame) History() []string {
+    return append([]string(nil), game.history...)
+}
+
+// Get the origin and destination of the last move, nil before the first move
+func (game ChessGame) LastMove() []string {
+    return game.board.getLastMove()
+}
+
+// Get the remaining time of both players, false when playing without clocks
+func (game ChessGame) RemainingTimes() (whiteTime, blackTime time.Duration, hasClocks bool) {
+    if game.clocks == nil {
+        return 0, 0, false
+    }
+    now := time.Now()
+    return game.clocks[white].Remaining(now), game.clocks[black].Remaining(now), true
+}
+
+// Get the FEN string of the current position. There are never en passant squares.
+func (game ChessGame) FEN() string {
+    return game.Position().FEN()
+}
+
+// Count the moves since the last capture or Pawn move
+func (game ChessGame) getHalfMoveClock() int {
+    count := 0
+    for i := len(game.history) - 1; i >= 0; i-- {
+        san := game.history[i]
+        if strings.Contains(san, "x") || (san[0] >= 'a' && san[0] <= 'h') { //Pawn moves start with the file
+            return count
+        }
+        count++
+    }
+    return count + game.startHalfMoves
+}
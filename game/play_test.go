@@ -0,0 +1,48 @@
+package game
+
+import (
+    "testing"
+    "time"
+)
+
+func TestUndoTakesBackIncrement(t *testing.T) {
+    game := newTestGame(t, StartFEN)
+    game.SetTimeControl(TimeControl{Base: 5 * time.Minute, Increment: 2 * time.Second})
+    game.StartClocks()
+
+    if _, err := game.Move("e2 e4"); err != nil {
+        t.Fatal(err)
+    }
+    if whiteTime, _, _ := game.RemainingTimes(); whiteTime <= 5*time.Minute {
+        t.Fatalf("increment not added: %v", whiteTime)
+    }
+
+    if err := game.Undo(); err != nil {
+        t.Fatal(err)
+    }
+    whiteTime, blackTime, _ := game.RemainingTimes()
+    if whiteTime > 5*time.Minute || whiteTime < 5*time.Minute-time.Second {
+        t.Errorf("White's clock after the take-back: %v, expected the base time less the time used", whiteTime)
+    }
+    if blackTime > 5*time.Minute {
+        t.Errorf("Black's clock after the take-back: %v", blackTime)
+    }
+    if game.clocks[white].movesMade != 0 {
+        t.Errorf("moves made after the take-back: %d", game.clocks[white].movesMade)
+    }
+}
+
+func TestUndoTakesBackSessionTime(t *testing.T) {
+    game := newTestGame(t, StartFEN)
+    game.SetTimeControl(TimeControl{Base: time.Minute, MovesPerSession: 1})
+    game.StartClocks()
+
+    game.Move("e2 e4")
+    if whiteTime, _, _ := game.RemainingTimes(); whiteTime <= time.Minute {
+        t.Fatalf("session time not added: %v", whiteTime)
+    }
+    game.Undo()
+    if whiteTime, _, _ := game.RemainingTimes(); whiteTime > time.Minute {
+        t.Errorf("session time kept after the take-back: %v", whiteTime)
+    }
+}
@@ -0,0 +1,111 @@
+package game
+
+import (
+    "errors"
+    "fmt"
+    "strings"
+)
+
+// Get a deep copy of the board that shares no squares, pieces or captures with it.
+// Moves made on the board before are not copied, so they cannot be taken back on the copy.
+func (board Board) Clone() *Board {
+    clone := NewBoard()
+    for i := 0; i < boardSize; i++ {
+        for j := 0; j < boardSize; j++ {
+            if piece := board.squares[i][j].getPiece(); piece != nil {
+                pieceCopy := *piece
+                clone.squares[i][j].setPiece(&pieceCopy)
+            }
+        }
+    }
+    clone.whiteCaptures = append([]string(nil), board.whiteCaptures...)
+    clone.blackCaptures = append([]string(nil), board.blackCaptures...)
+    clone.dropsAllowed = board.dropsAllowed
+    clone.castling = board.castling
+    clone.chess960 = board.chess960
+    return clone
+}
+
+// MARK: Position (immutable snapshot of a game, safe to share between goroutines)
+// A Position never changes after it's created, Play() returns a new one.
+type Position struct {
+    board      *Board //private copy, never changed
+    sideToMove Team
+    halfMoves  int //moves since the last capture or Pawn move
+    moveNumber int
+}
+
+// Get the position of the game, later moves in the game don't change it
+func (game ChessGame) Position() Position {
+    moveNumber := game.startMoveNumber
+    if moveNumber == 0 {
+        moveNumber = 1
+    }
+    plies := len(game.history)
+    if game.blackStarts {
+        plies++
+    }
+    return Position{game.board.Clone(), game.SideToMove(), game.getHalfMoveClock(), moveNumber + plies/2}
+}
+
+func NewPositionFromFEN(fen string) (Position, error) {
+    game, err := NewFromFEN(fen)
+    if err != nil {
+        return Position{}, err
+    }
+    return game.Position(), nil
+}
+
+func (position Position) SideToMove() Team {
+    return position.sideToMove
+}
+
+// Get the FEN string, there are never en passant squares. Castling rights are in X-FEN, Crazyhouse pockets follow the placement.
+func (position Position) FEN() string {
+    side := "w"
+    if position.sideToMove == black {
+        side = "b"
+    }
+    placement := position.board.FENPlacement()
+    if position.board.dropsAllowed {
+        placement += position.board.FENPocket()
+    }
+    return fmt.Sprintf("%s %s %s - %d %d", placement, side, position.board.FENCastling(), position.halfMoves, position.moveNumber)
+}
+
+func (position Position) LegalMoves() []string {
+    return position.board.getAvailableMoves(position.sideToMove)
+}
+
+func (position Position) InCheck() bool {
+    return position.board.inCheck(position.sideToMove)
+}
+
+// Get a copy of the board, e.g. to draw it
+func (position Position) Board() *Board {
+    return position.board.Clone()
+}
+
+// Make the move ("e2 e4", a castling or a "N@f3" drop) on a copy and return the new position and the move in SAN
+func (position Position) Play(move string) (next Position, san string, err error) {
+    defer func() {
+        if recovered := recover(); recovered != nil {
+            next, san, err = Position{}, "", fmt.Errorf("%v", recovered)
+        }
+    }()
+    if position.board == nil {
+        return Position{}, "", errors.New("empty position")
+    }
+
+    board := position.board.Clone()
+    san, _ = board.execute(strings.TrimSpace(move), position.sideToMove)
+
+    next = Position{board, getOpponentTeam(position.sideToMove), position.halfMoves + 1, position.moveNumber}
+    if strings.Contains(san, "x") || (san[0] >= 'a' && san[0] <= 'h') { //capture or Pawn move
+        next.halfMoves = 0
+    }
+    if position.sideToMove == black {
+        next.moveNumber++
+    }
+    return next, san, nil
+}
@@ -0,0 +1,85 @@
+package game
+
+import (
+    "sort"
+    "strconv"
+    "sync"
+    "time"
+)
+
+// MARK: SessionManager (hosts many games at once, e.g. for a server)
+// Each game is a Session with its own lock, so games are played concurrently
+// and only requests on the same game wait for each other.
+type SessionManager struct {
+    mutex    sync.RWMutex //guards sessions and nextID, not the games
+    sessions map[string]*Session
+    nextID   int
+}
+
+type Session struct {
+    mutex   sync.Mutex
+    id      string
+    created time.Time
+    game    ChessGame
+}
+
+func NewSessionManager() *SessionManager {
+    return &SessionManager{sessions: make(map[string]*Session)}
+}
+
+// Host the game in a new session, the game must not be used outside of the session afterwards
+func (manager *SessionManager) Create(game ChessGame) *Session {
+    manager.mutex.Lock()
+    defer manager.mutex.Unlock()
+    manager.nextID++
+    session := &Session{id: strconv.Itoa(manager.nextID), created: time.Now(), game: game}
+    manager.sessions[session.id] = session
+    return session
+}
+
+func (manager *SessionManager) Get(id string) (*Session, bool) {
+    manager.mutex.RLock()
+    defer manager.mutex.RUnlock()
+    session, found := manager.sessions[id]
+    return session, found
+}
+
+// Get all sessions, oldest first
+func (manager *SessionManager) List() []*Session {
+    manager.mutex.RLock()
+    sessions := make([]*Session, 0, len(manager.sessions))
+    for _, session := range manager.sessions {
+        sessions = append(sessions, session)
+    }
+    manager.mutex.RUnlock()
+
+    sort.Slice(sessions, func(i, j int) bool {
+        return sessions[i].created.Before(sessions[j].created)
+    })
+    return sessions
+}
+
+// Stop hosting the session, return false if there is none with the id
+func (manager *SessionManager) Remove(id string) bool {
+    manager.mutex.Lock()
+    defer manager.mutex.Unlock()
+    _, found := manager.sessions[id]
+    delete(manager.sessions, id)
+    return found
+}
+
+func (session *Session) ID() string {
+    return session.id
+}
+
+func (session *Session) Created() time.Time {
+    return session.created
+}
+
+// Run the function with the game locked, no other goroutine uses the game until it returns.
+// The game must not be kept after the function returns, take its Position() to keep a snapshot.
+func (session *Session) Do(function func(game *ChessGame) error) error {
+    session.mutex.Lock()
+    defer session.mutex.Unlock()
+    return function(&session.game)
+}
@@ -0,0 +1,165 @@
+package game
+
+import (
+    "sync"
+    "testing"
+)
+
+// Meant to run with -race, the goroutines only share what the sessions and positions guard
+const concurrentClients = 8
+
+func TestSessionManagerConcurrentCreateGetList(t *testing.T) {
+    manager := NewSessionManager()
+    ids := make([]string, concurrentClients)
+    games := make([]ChessGame, concurrentClients)
+    for i := range games {
+        games[i] = newTestGame(t, StartFEN)
+    }
+
+    var wait sync.WaitGroup
+    for i := 0; i < concurrentClients; i++ {
+        wait.Add(1)
+        go func(i int) {
+            defer wait.Done()
+            session := manager.Create(games[i])
+            ids[i] = session.ID()
+            if found, ok := manager.Get(session.ID()); !ok || found != session {
+                t.Errorf("session %s not found after its creation", session.ID())
+            }
+            manager.List()
+        }(i)
+    }
+    wait.Wait()
+
+    unique := make(map[string]bool)
+    for _, id := range ids {
+        unique[id] = true
+    }
+    if len(unique) != concurrentClients || len(manager.List()) != concurrentClients {
+        t.Errorf("expected %d distinct sessions, got ids %v and %d listed", concurrentClients, ids, len(manager.List()))
+    }
+
+    list := manager.List()
+    for i := 1; i < len(list); i++ {
+        if list[i].Created().Before(list[i-1].Created()) {
+            t.Error("sessions must be listed oldest first")
+        }
+    }
+
+    if !manager.Remove(ids[0]) || manager.Remove(ids[0]) {
+        t.Error("a session is only removed once")
+    }
+    if _, found := manager.Get(ids[0]); found {
+        t.Error("removed session still found")
+    }
+}
+
+func TestSessionDoOnSharedSession(t *testing.T) {
+    manager := NewSessionManager()
+    session := manager.Create(newTestGame(t, StartFEN))
+
+    const movesPerClient = 4
+    var wait sync.WaitGroup
+    for i := 0; i < concurrentClients; i++ {
+        wait.Add(1)
+        go func() {
+            defer wait.Done()
+            for j := 0; j < movesPerClient; j++ {
+                err := session.Do(func(game *ChessGame) error {
+                    if game.Outcome() != nil {
+                        return nil
+                    }
+                    //The legal moves can't change between listing them and moving while the session is locked
+                    moves := game.LegalMoves()
+                    _, err := game.Move(moves[len(moves)/2])
+                    return err
+                })
+                if err != nil {
+                    t.Error(err)
+                }
+            }
+        }()
+    }
+    wait.Wait()
+
+    session.Do(func(game *ChessGame) error {
+        if game.Outcome() == nil && len(game.History()) != concurrentClients*movesPerClient {
+            t.Errorf("expected %d moves, got %d", concurrentClients*movesPerClient, len(game.History()))
+        }
+        return nil
+    })
+}
+
+func TestSessionDoOnSeparateSessions(t *testing.T) {
+    manager := NewSessionManager()
+    moves := []string{"e2 e4", "e7 e5", "g1 f3", "b8 c6", "f1 b5"}
+    for i := 0; i < concurrentClients; i++ {
+        manager.Create(newTestGame(t, StartFEN))
+    }
+
+    var wait sync.WaitGroup
+    for _, session := range manager.List() {
+        wait.Add(1)
+        go func(session *Session) {
+            defer wait.Done()
+            for _, move := range moves {
+                err := session.Do(func(game *ChessGame) error {
+                    _, err := game.Move(move)
+                    return err
+                })
+                if err != nil {
+                    t.Errorf("session %s: %s %v", session.ID(), move, err)
+                }
+            }
+        }(session)
+    }
+    wait.Wait()
+
+    for _, session := range manager.List() {
+        session.Do(func(game *ChessGame) error {
+            if len(game.History()) != len(moves) {
+                t.Errorf("session %s has %d moves, the games must not see each other's moves", session.ID(), len(game.History()))
+            }
+            return nil
+        })
+    }
+}
+
+func TestSharedPositionIsImmutable(t *testing.T) {
+    game := newTestGame(t, StartFEN)
+    position := game.Position()
+    fen := position.FEN()
+    moves := position.LegalMoves()
+
+    var wait sync.WaitGroup
+    for i := 0; i < concurrentClients; i++ {
+        wait.Add(1)
+        go func(i int) {
+            defer wait.Done()
+            for j := i; j < len(moves); j += concurrentClients {
+                next, _, err := position.Play(moves[j])
+                if err != nil {
+                    t.Error(err)
+                    continue
+                }
+                //Keep going from the new position, it must not share anything with the old one
+                if reply := next.LegalMoves(); len(reply) > 0 {
+                    next.Play(reply[0])
+                }
+                position.InCheck()
+                position.Board()
+            }
+        }(i)
+    }
+
+    //Moves in the game the position was taken from don't change it either
+    game.Move("d2 d4")
+    wait.Wait()
+
+    if position.FEN() != fen || len(position.LegalMoves()) != len(moves) {
+        t.Errorf("shared position changed: %s", position.FEN())
+    }
+    if len(moves) != 20 {
+        t.Errorf("expected 20 moves from the start, got %d", len(moves))
+    }
+}
@@ -0,0 +1,172 @@
+package game
+
+import (
+    "bytes"
+    "fmt"
+    "html"
+    "math"
+    "strings"
+)
+
+// MARK: SVGOptions (how Board.SVG() draws the board)
+type SVGOptions struct {
+    Size           int    //width and height in pixels
+    LightColor     string //color of light squares, any SVG color
+    DarkColor      string
+    Coordinates    bool   //draw file letters and rank numbers inside the edge squares
+    Flipped        bool   //draw from Black's side
+    HighlightCheck bool   //highlight a King in check with CheckColor
+    CheckColor     string
+    Highlights     map[string]string //position ("e4") to color of squares to highlight, e.g. last move
+    Arrows         []SVGArrow
+}
+
+type SVGArrow struct {
+    From, To string //positions, e.g. "e2" and "e4"
+    Color    string
+}
+
+func DefaultSVGOptions() SVGOptions {
+    return SVGOptions{
+        Size:           400,
+        LightColor:     "#f0d9b5",
+        DarkColor:      "#b58863",
+        Coordinates:    true,
+        HighlightCheck: true,
+        CheckColor:     "#e04040",
+    }
+}
+
+// Draw the board as an SVG image. Pieces are drawn as chess glyphs,
+// so the viewer needs a font that has them (most systems do).
+func (board Board) SVG(options SVGOptions) string {
+    var buffer bytes.Buffer
+
+    size := options.Size
+    if size <= 0 {
+        size = DefaultSVGOptions().Size
+    }
+    square := float64(size) / boardSize
+
+    buffer.WriteString(fmt.Sprintf(`<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d">`+"\n", size, size, size, size))
+
+    //Arrow heads, one marker per arrow so each can have its own color
+    if len(options.Arrows) > 0 {
+        buffer.WriteString("<defs>\n")
+        for i, arrow := range options.Arrows {
+            buffer.WriteString(fmt.Sprintf(`<marker id="arrowhead-%d" markerWidth="4" markerHeight="4" refX="2" refY="2" orient="auto"><path d="M0,0 L4,2 L0,4 z" fill="%s"/></marker>`+"\n", i, html.EscapeString(arrow.Color)))
+        }
+        buffer.WriteString("</defs>\n")
+    }
+
+    highlights := make(map[string]string)
+    for position, color := range options.Highlights {
+        highlights[position] = color
+    }
+    if options.HighlightCheck {
+        for _, team := range []Team{white, black} {
+            if board.countKings(team) == 1 && board.inCheck(team) {
+                highlights[board.getKingPosition(team)] = options.CheckColor
+            }
+        }
+    }
+
+    //Squares
+    for i := 0; i < boardSize; i++ {
+        for j := 0; j < boardSize; j++ {
+            x, y := svgSquareOrigin(i, j, square, options.Flipped)
+            color := options.DarkColor
+            if (i+j)%2 == 0 {
+                color = options.LightColor
+            }
+            buffer.WriteString(fmt.Sprintf(`<rect x="%.2f" y="%.2f" width="%.2f" height="%.2f" fill="%s"/>`+"\n", x, y, square, square, html.EscapeString(color)))
+
+            if highlight, found := highlights[getCoordinatePosition(i, j)]; found {
+                buffer.WriteString(fmt.Sprintf(`<rect x="%.2f" y="%.2f" width="%.2f" height="%.2f" fill="%s" fill-opacity="0.5"/>`+"\n", x, y, square, square, html.EscapeString(highlight)))
+            }
+        }
+    }
+
+    //Coordinates, drawn in the color of the other square color for contrast
+    if options.Coordinates {
+        fontSize := square * 0.2
+        for n := 0; n < boardSize; n++ {
+            //Files along the bottom edge
+            i, j := boardSize-1, n
+            if options.Flipped {
+                i, j = 0, boardSize-1-n
+            }
+            x, y := svgSquareOrigin(i, j, square, options.Flipped)
+            buffer.WriteString(fmt.Sprintf(`<text x="%.2f" y="%.2f" font-family="sans-serif" font-size="%.2f" text-anchor="end" fill="%s">%c</text>`+"\n",
+                x+square*0.95, y+square*0.95, fontSize, html.EscapeString(svgContrastColor(i, j, options)), 'a'+j))
+
+            //Ranks along the left edge
+            i, j = n, 0
+            if options.Flipped {
+                i, j = boardSize-1-n, boardSize-1
+            }
+            x, y = svgSquareOrigin(i, j, square, options.Flipped)
+            buffer.WriteString(fmt.Sprintf(`<text x="%.2f" y="%.2f" font-family="sans-serif" font-size="%.2f" fill="%s">%d</text>`+"\n",
+                x+square*0.05, y+square*0.25, fontSize, html.EscapeString(svgContrastColor(i, j, options)), boardSize-i))
+        }
+    }
+
+    //Pieces, both sides use the filled glyph: White in white with a dark outline, Black in black
+    for i := 0; i < boardSize; i++ {
+        for j := 0; j < boardSize; j++ {
+            piece := board.squares[i][j].getPiece()
+            if piece == nil {
+                continue
+            }
+            x, y := svgSquareOrigin(i, j, square, options.Flipped)
+            fill := "#000000"
+            if piece.team == white {
+                fill = "#ffffff"
+            }
+            buffer.WriteString(fmt.Sprintf(`<text x="%.2f" y="%.2f" font-size="%.2f" text-anchor="middle" dominant-baseline="central" fill="%s" stroke="#000000" stroke-width="%.2f" paint-order="stroke">%s</text>`+"\n",
+                x+square/2, y+square/2, square*0.8, fill, square*0.03, getPieceSymbol(strings.ToUpper(piece.sign)))) //upper case signs have the filled glyphs
+        }
+    }
+
+    //Arrows on top of everything
+    for i, arrow := range options.Arrows {
+        if len(arrow.From) != 2 || len(arrow.To) != 2 {
+            continue
+        }
+        from := board.getSquare(arrow.From)
+        to := board.getSquare(arrow.To)
+        if from == nil || to == nil || from == to {
+            continue
+        }
+        x1, y1 := svgSquareOrigin(from.row, from.col, square, options.Flipped)
+        x2, y2 := svgSquareOrigin(to.row, to.col, square, options.Flipped)
+        x1, y1, x2, y2 = x1+square/2, y1+square/2, x2+square/2, y2+square/2
+
+        //Stop the line short so the arrow head ends inside the target square
+        dx, dy := x2-x1, y2-y1
+        length := math.Hypot(dx, dy)
+        shorten := square * 0.3
+        x2, y2 = x2-dx/length*shorten, y2-dy/length*shorten
+
+        buffer.WriteString(fmt.Sprintf(`<line x1="%.2f" y1="%.2f" x2="%.2f" y2="%.2f" stroke="%s" stroke-width="%.2f" stroke-opacity="0.8" stroke-linecap="round" marker-end="url(#arrowhead-%d)"/>`+"\n",
+            x1, y1, x2, y2, html.EscapeString(arrow.Color), square*0.15, i))
+    }
+
+    buffer.WriteString("</svg>\n")
+    return buffer.String()
+}
+
+// Get the top left corner of the square at (row, col)
+func svgSquareOrigin(row, col int, square float64, flipped bool) (float64, float64) {
+    if flipped {
+        row, col = boardSize-1-row, boardSize-1-col
+    }
+    return float64(col) * square, float64(row) * square
+}
+
+func svgContrastColor(row, col int, options SVGOptions) string {
+    if (row+col)%2 == 0 {
+        return options.DarkColor
+    }
+    return options.LightColor
+}
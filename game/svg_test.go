@@ -0,0 +1,106 @@
+package game
+
+import (
+    "encoding/xml"
+    "io"
+    "strings"
+    "testing"
+)
+
+// Check the SVG is well-formed XML and count its elements by name
+func countSVGElements(t *testing.T, svg string) map[string]int {
+    counts := make(map[string]int)
+    decoder := xml.NewDecoder(strings.NewReader(svg))
+    for {
+        token, err := decoder.Token()
+        if err == io.EOF {
+            return counts
+        }
+        if err != nil {
+            t.Fatalf("invalid SVG: %v\n%s", err, svg)
+        }
+        if start, ok := token.(xml.StartElement); ok {
+            counts[start.Name.Local]++
+        }
+    }
+}
+
+func TestSVG(t *testing.T) {
+    //Black Rook on h1 checks the White King on e1, squares are 50 pixels wide
+    board, err := NewBoardFromFEN("4k3/8/8/8/8/8/4P3/4K2r w - - 0 1")
+    if err != nil {
+        t.Fatal(err)
+    }
+    options := DefaultSVGOptions()
+    options.Highlights = map[string]string{"e4": "#aaff00"}
+    options.Arrows = []SVGArrow{
+        {"e2", "e4", "#00aa00"},
+        {"e2", "e9", "red"}, //off the board
+        {"e", "e4", "red"},
+        {"e2", "e2", "red"}, //no length
+    }
+
+    tests := []struct {
+        name     string
+        flipped  bool
+        expected []string
+    }{
+        {"White's side", false, []string{
+            `<rect x="200.00" y="200.00" width="50.00" height="50.00" fill="#aaff00" fill-opacity="0.5"/>`, //e4 highlighted
+            `<rect x="200.00" y="350.00" width="50.00" height="50.00" fill="#e04040" fill-opacity="0.5"/>`, //King in check on e1
+            `<rect x="0.00" y="0.00" width="50.00" height="50.00" fill="#f0d9b5"/>`,                         //a8 is light
+            `x="47.50" y="397.50" font-family="sans-serif" font-size="10.00" text-anchor="end" fill="#f0d9b5">a</text>`, //a1 is dark
+            `x="2.50" y="12.50" font-family="sans-serif" font-size="10.00" fill="#b58863">8</text>`,
+            `<text x="225.00" y="375.00" font-size="40.00"`, //White King on e1
+            `<line x1="225.00" y1="325.00" x2="225.00" y2="240.00" stroke="#00aa00"`,
+            `<marker id="arrowhead-0"`,
+        }},
+        {"Black's side", true, []string{
+            `<rect x="150.00" y="150.00" width="50.00" height="50.00" fill="#aaff00" fill-opacity="0.5"/>`,
+            `<rect x="150.00" y="0.00" width="50.00" height="50.00" fill="#e04040" fill-opacity="0.5"/>`,
+            `<rect x="0.00" y="0.00" width="50.00" height="50.00" fill="#f0d9b5"/>`, //h1 is light too
+            `x="47.50" y="397.50" font-family="sans-serif" font-size="10.00" text-anchor="end" fill="#f0d9b5">h</text>`,
+            `x="397.50" y="397.50" font-family="sans-serif" font-size="10.00" text-anchor="end" fill="#b58863">a</text>`,
+            `x="2.50" y="12.50" font-family="sans-serif" font-size="10.00" fill="#b58863">1</text>`,
+            `<text x="175.00" y="25.00" font-size="40.00"`,
+            `<line x1="175.00" y1="75.00" x2="175.00" y2="160.00" stroke="#00aa00"`,
+        }},
+    }
+
+    for _, test := range tests {
+        options.Flipped = test.flipped
+        svg := board.SVG(options)
+        for _, expected := range test.expected {
+            if !strings.Contains(svg, expected) {
+                t.Errorf("%s: missing %s", test.name, expected)
+            }
+        }
+
+        //64 squares, the highlight and the check, 16 coordinates and 4 pieces, one arrow drawn out of four
+        counts := countSVGElements(t, svg)
+        if counts["rect"] != 66 || counts["text"] != 20 || counts["line"] != 1 || counts["marker"] != 4 {
+            t.Errorf("%s: elements %v", test.name, counts)
+        }
+    }
+}
+
+func TestSVGOptions(t *testing.T) {
+    board, err := NewBoardFromFEN("4k3/8/8/8/8/8/8/4K2r w - - 0 1")
+    if err != nil {
+        t.Fatal(err)
+    }
+    options := SVGOptions{Size: 80, LightColor: "white", DarkColor: `"><script>`}
+    svg := board.SVG(options)
+
+    if !strings.HasPrefix(svg, `<svg xmlns="http://www.w3.org/2000/svg" width="80" height="80" viewBox="0 0 80 80">`) {
+        t.Errorf("size not applied: %s", svg[:strings.Index(svg, "\n")])
+    }
+    counts := countSVGElements(t, svg) //colors are escaped
+    if counts["rect"] != 64 || counts["text"] != 3 || counts["script"] != 0 {
+        t.Errorf("without coordinates and check highlight: elements %v", counts)
+    }
+
+    if svg := board.SVG(SVGOptions{}); !strings.Contains(svg, `width="400"`) {
+        t.Error("the default size is used without one")
+    }
+}
@@ -2,19 +2,156 @@ package main
 
 import (
     "github.com/dilyar85/chess/game"
+    "github.com/dilyar85/chess/utils"
+    "flag"
+    "fmt"
+    "math/rand"
     "os"
+    "strings"
+    "time"
 )
 
 func main() {
+    if len(os.Args) > 1 {
+        switch os.Args[1] {
+        case "render":
+            runRender(os.Args[2:])
+            return
+        case "serve":
+            runServe(os.Args[2:])
+            return
+        case "host":
+            runHost(os.Args[2:])
+            return
+        case "join":
+            runJoin(os.Args[2:])
+            return
+        }
+    }
+
+    timeControl := addTimeControlFlags(flag.CommandLine)
+    display := addDisplayFlags(flag.CommandLine)
+    autoFlip := flag.Bool("autoflip", false, "draw the board from the side of the player to move (for hot-seat play)")
+    variant := flag.String("variant", "standard", "rules to play: standard, chess960 or crazyhouse")
+    position960 := flag.Int("960", -1, "Chess960 start position (0-959, 518 is the standard one), random if -1")
+    seed := flag.Int64("seed", 0, "seed for the random Chess960 start position, for repeating a position (time based if 0)")
+    pgnFile := addPGNFlag(flag.CommandLine)
+    flag.Parse()
+
     chessGame := game.New()
+    switch *variant {
+    case "standard":
+    case "chess960":
+        chessGame = newChess960Game(*position960, *seed)
+    case "crazyhouse":
+        chessGame = game.NewCrazyhouse()
+    default:
+        exitWithError("Unknown variant:", *variant, "(available: standard, chess960, crazyhouse)")
+    }
+    chessGame.SetRenderer(display.renderer())
+    chessGame.SetAutoFlip(*autoFlip)
+    timeControl.apply(&chessGame)
 
-    arg := os.Args
-    isInteractiveMode := len(arg) <= 1
+    isInteractiveMode := flag.NArg() == 0
 
     if isInteractiveMode {
-        chessGame.StartInteractiveMode()
+        outcome := chessGame.StartInteractiveMode()
+        savePGN(*pgnFile, chessGame, outcome)
     } else {
-        chessGame.StartFileMode(arg[1])
+        chessGame.StartFileMode(flag.Arg(0))
+    }
+
+}
+
+// Create a Chess960 game from the position index, or from a random position if it's negative
+func newChess960Game(index int, seed int64) game.ChessGame {
+    if index < 0 {
+        if seed == 0 {
+            seed = time.Now().UnixNano()
+        }
+        index = rand.New(rand.NewSource(seed)).Intn(game.Chess960Positions)
+    }
+    chessGame, err := game.NewChess960(index)
+    if err != nil {
+        exitWithError("Invalid Chess960 position:", err)
+    }
+    fmt.Println("Chess960 position", index)
+    return chessGame
+}
+
+// MARK: Flags shared by the commands that play games
+type timeControlFlags struct {
+    baseTime, increment, delay *time.Duration
+    movesPerSession            *int
+}
+
+func addTimeControlFlags(flags *flag.FlagSet) timeControlFlags {
+    return timeControlFlags{
+        baseTime:        flags.Duration("time", 0, "base time on each player's clock, e.g. 5m (no clock if 0)"),
+        increment:       flags.Duration("inc", 0, "Fischer increment added after each move, e.g. 2s"),
+        delay:           flags.Duration("delay", 0, "Bronstein delay given back after each move, e.g. 3s"),
+        movesPerSession: flags.Int("moves", 0, "moves per time session, the base time is added again after them (0 for sudden death)"),
+    }
+}
+
+// Set the time control on the game, if a base time was given
+func (control timeControlFlags) apply(chessGame *game.ChessGame) {
+    if *control.baseTime > 0 {
+        chessGame.SetTimeControl(game.TimeControl{
+            Base:            *control.baseTime,
+            Increment:       *control.increment,
+            Delay:           *control.delay,
+            MovesPerSession: *control.movesPerSession,
+        })
+    }
+}
+
+type displayFlags struct {
+    ascii, plain *bool
+    themeName    *string
+}
+
+func addDisplayFlags(flags *flag.FlagSet) displayFlags {
+    return displayFlags{
+        ascii:     flags.Bool("ascii", false, "draw pieces as letters (upper case for White) for terminals without chess glyphs"),
+        plain:     flags.Bool("plain", false, "draw the board without colors (default when output is not a terminal)"),
+        themeName: flags.String("theme", utils.Themes[0].Name, "board colors: " + themeNames()),
+    }
+}
+
+// Get the renderer for the flags, exit if the theme is unknown
+func (display displayFlags) renderer() utils.Renderer {
+    theme, found := utils.FindTheme(*display.themeName)
+    if !found {
+        fmt.Println("Unknown theme:", *display.themeName, "(available: " + themeNames() + ")")
+        os.Exit(2)
     }
+    return utils.Renderer{
+        Colored: !*display.plain && os.Getenv("NO_COLOR") == "" && utils.IsTerminal(os.Stdout),
+        ASCII:   *display.ascii,
+        Theme:   theme,
+    }
+}
+
+func addPGNFlag(flags *flag.FlagSet) *string {
+    return flags.String("pgn", "", "file to save the game to in PGN when it ends")
+}
+
+// Save the finished game to the file in PGN, nothing to do if no file was given
+func savePGN(path string, chessGame game.ChessGame, outcome game.Outcome) {
+    if path == "" {
+        return
+    }
+    if err := os.WriteFile(path, []byte(chessGame.PGN()), 0644); err != nil {
+        exitWithError("Unable to save the game:", err)
+    }
+    fmt.Println("Game saved to", path, "("+outcome.Result()+")")
+}
 
-}
\ No newline at end of file
+func themeNames() string {
+    var names []string
+    for _, theme := range utils.Themes {
+        names = append(names, theme.Name)
+    }
+    return strings.Join(names, ", ")
+}
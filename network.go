@@ -0,0 +1,79 @@
+package main
+
+import (
+    "github.com/dilyar85/chess/game"
+    "flag"
+    "fmt"
+    "math/rand"
+    "net"
+)
+
+// "host" command: wait for an opponent to join over TCP and play them
+func runHost(args []string) {
+    flags := flag.NewFlagSet("host", flag.ExitOnError)
+    addr := flags.String("addr", ":7777", "address to listen on")
+    color := flags.String("color", "white", "your color: white, black or random")
+    timeControl := addTimeControlFlags(flags)
+    display := addDisplayFlags(flags)
+    pgnFile := addPGNFlag(flags)
+    flags.Parse(args)
+
+    if *color == "random" {
+        *color = []string{"white", "black"}[rand.Intn(2)]
+    }
+    team, err := game.ParseTeam(*color)
+    if err != nil {
+        exitWithError("Invalid color:", *color)
+    }
+
+    chessGame := game.New()
+    chessGame.SetRenderer(display.renderer())
+    timeControl.apply(&chessGame)
+
+    listener, err := net.Listen("tcp", *addr)
+    if err != nil {
+        exitWithError("Unable to listen:", err)
+    }
+    fmt.Println("Waiting for an opponent on", listener.Addr(), "...")
+    conn, err := listener.Accept()
+    listener.Close() //one opponent per game
+    if err != nil {
+        exitWithError("Unable to accept the opponent:", err)
+    }
+    fmt.Println("Opponent joined from", conn.RemoteAddr())
+
+    outcome, err := chessGame.HostNetworkGame(conn, team)
+    if err != nil {
+        exitWithError("Unable to start the game:", err)
+    }
+    savePGN(*pgnFile, chessGame, outcome)
+}
+
+// "join" command: play the game hosted at the address
+func runJoin(args []string) {
+    flags := flag.NewFlagSet("join", flag.ExitOnError)
+    display := addDisplayFlags(flags)
+    pgnFile := addPGNFlag(flags)
+    flags.Usage = func() {
+        fmt.Fprintln(flags.Output(), "Usage: join [options] host:port")
+        flags.PrintDefaults()
+    }
+    flags.Parse(args)
+    if flags.NArg() != 1 {
+        flags.Usage()
+        return
+    }
+
+    conn, err := net.Dial("tcp", flags.Arg(0))
+    if err != nil {
+        exitWithError("Unable to connect:", err)
+    }
+
+    chessGame := game.New()
+    chessGame.SetRenderer(display.renderer())
+    outcome, err := chessGame.JoinNetworkGame(conn)
+    if err != nil {
+        exitWithError("Unable to start the game:", err)
+    }
+    savePGN(*pgnFile, chessGame, outcome)
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+    "github.com/dilyar85/chess/game"
+    "errors"
+    "flag"
+    "fmt"
+    "image/color"
+    "os"
+    "path/filepath"
+    "strconv"
+    "strings"
+    "time"
+)
+
+// "render" command: draw a position (FEN or the end of a playbook game) to SVG or PNG,
+// or a whole playbook game to an animated GIF
+func runRender(args []string) {
+    defaults := game.DefaultSVGOptions()
+
+    flags := flag.NewFlagSet("render", flag.ExitOnError)
+    fen := flags.String("fen", game.StartFEN, "position to draw")
+    playbook := flags.String("playbook", "", "playbook file to replay instead of -fen, the last move is highlighted")
+    output := flags.String("o", "board.svg", "output file, the format is taken from the extension: .svg, .png or .gif (playbook only)")
+    size := flags.Int("size", defaults.Size, "width and height in pixels")
+    light := flags.String("light", defaults.LightColor, "color of light squares")
+    dark := flags.String("dark", defaults.DarkColor, "color of dark squares")
+    coordinates := flags.Bool("coords", defaults.Coordinates, "draw file letters and rank numbers (SVG only)")
+    flipped := flags.Bool("flip", false, "draw from Black's side")
+    highlights := flags.String("highlight", "", "comma separated squares to highlight, e.g. e2,e4")
+    highlightColor := flags.String("highlight-color", "#cdd26a", "color of highlighted squares")
+    arrows := flags.String("arrows", "", "comma separated arrows, e.g. e2e4,g1f3 (SVG only)")
+    arrowColor := flags.String("arrow-color", "#15781b", "color of arrows")
+    delay := flags.Duration("delay", time.Second, "time each position is shown in a GIF")
+    flags.Parse(args)
+
+    if *size < 8 {
+        exitWithError("Invalid size, the board needs at least one pixel per square:", *size)
+    }
+
+    var highlightedPositions []string
+    for _, position := range splitList(*highlights) {
+        if !isPosition(position) {
+            exitWithError("Invalid square to highlight:", position)
+        }
+        highlightedPositions = append(highlightedPositions, position)
+    }
+
+    //Position to draw
+    var board *game.Board
+    var err error
+    if *playbook != "" {
+        var lastMove string
+        board, lastMove, err = game.ReplayPlaybook(*playbook, nil)
+        if err != nil {
+            exitWithError("Unable to replay", *playbook+":", err)
+        }
+        highlightedPositions = append(highlightedPositions, strings.Fields(lastMove)...)
+    } else {
+        board, err = game.NewBoardFromFEN(*fen)
+        if err != nil {
+            exitWithError("Invalid FEN:", err)
+        }
+    }
+
+    file, err := os.Create(*output)
+    if err != nil {
+        exitWithError("Unable to create", *output+":", err)
+    }
+    defer file.Close()
+
+    extension := strings.ToLower(filepath.Ext(*output))
+    switch extension {
+    case ".svg":
+        options := defaults
+        options.Size = *size
+        options.LightColor = *light
+        options.DarkColor = *dark
+        options.Coordinates = *coordinates
+        options.Flipped = *flipped
+        options.Highlights = make(map[string]string)
+        for _, position := range highlightedPositions {
+            options.Highlights[position] = *highlightColor
+        }
+        for _, arrow := range splitList(*arrows) {
+            if len(arrow) != 4 || !isPosition(arrow[:2]) || !isPosition(arrow[2:]) {
+                exitWithError("Invalid arrow:", arrow)
+            }
+            options.Arrows = append(options.Arrows, game.SVGArrow{From: arrow[:2], To: arrow[2:], Color: *arrowColor})
+        }
+        _, err = file.WriteString(board.SVG(options))
+
+    case ".png", ".gif":
+        options := game.DefaultImageOptions()
+        options.SquareSize = *size / 8
+        options.Flipped = *flipped
+        options.LightColor = parseColorOrExit(*light)
+        options.DarkColor = parseColorOrExit(*dark)
+        options.HighlightColor = parseColorOrExit(*highlightColor)
+
+        if extension == ".png" {
+            err = board.WritePNG(file, options, highlightedPositions)
+        } else if *playbook == "" {
+            err = errors.New("a GIF needs a game, use -playbook")
+        } else {
+            err = game.WritePlaybookGIF(file, *playbook, options, *delay)
+        }
+
+    default:
+        err = errors.New("unknown image format, use .svg, .png or .gif")
+    }
+
+    if err != nil {
+        file.Close()
+        os.Remove(*output)
+        exitWithError("Unable to write", *output+":", err)
+    }
+    fmt.Println("Board saved to", *output)
+}
+
+// MARK: Helper functions for commands
+func splitList(list string) []string {
+    var items []string
+    for _, item := range strings.Split(list, ",") {
+        if item = strings.TrimSpace(item); item != "" {
+            items = append(items, item)
+        }
+    }
+    return items
+}
+
+func isPosition(position string) bool {
+    return len(position) == 2 && position[0] >= 'a' && position[0] <= 'h' && position[1] >= '1' && position[1] <= '8'
+}
+
+// Parse a "#rrggbb" color
+func parseColorOrExit(hex string) color.RGBA {
+    value, err := strconv.ParseUint(strings.TrimPrefix(hex, "#"), 16, 32)
+    if len(hex) != 7 || hex[0] != '#' || err != nil {
+        exitWithError("Invalid color, use #rrggbb for images:", hex)
+    }
+    return color.RGBA{uint8(value >> 16), uint8(value >> 8), uint8(value), 0xff}
+}
+
+func exitWithError(message ...interface{}) {
+    fmt.Fprintln(os.Stderr, message...)
+    os.Exit(1)
+}
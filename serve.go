@@ -0,0 +1,24 @@
+package main
+
+import (
+    "github.com/dilyar85/chess/server"
+    "flag"
+    "fmt"
+    "net/http"
+)
+
+// "serve" command: host games over the HTTP JSON API
+func runServe(args []string) {
+    flags := flag.NewFlagSet("serve", flag.ExitOnError)
+    addr := flags.String("addr", ":8080", "address to listen on")
+    origins := flags.String("origins", "", "comma separated origins of other sites whose pages may open WebSockets, e.g. https://chess.example.com")
+    flags.Parse(args)
+
+    gameServer := server.New()
+    gameServer.AllowOrigins(splitList(*origins)...)
+
+    fmt.Println("Serving games on", *addr)
+    if err := http.ListenAndServe(*addr, gameServer); err != nil {
+        exitWithError("Unable to serve:", err)
+    }
+}
@@ -0,0 +1,103 @@
+package server
+
+import (
+    "crypto/rand"
+    "encoding/hex"
+    "errors"
+    "github.com/dilyar85/chess/game"
+    "net/http"
+    "net/url"
+)
+
+// MARK: Players (who may change a game)
+// Creating a game hands out a secret token for each side. Every change to a game, over HTTP or WebSocket,
+// needs the token of one of its players passed as ?token=..., and players only act for their own side.
+// WebSocket clients without a token are spectators.
+
+type player struct {
+    gameID string
+    team   game.Team
+}
+
+// Tokens of a new game, only sent in the answer to its creation
+type playerTokens struct {
+    White string `json:"white"`
+    Black string `json:"black"`
+}
+
+var (
+    whiteTeam, _ = game.ParseTeam("white")
+    blackTeam, _ = game.ParseTeam("black")
+
+    errTokenRequired = errors.New("a player token is required, pass ?token=")
+    errUnknownToken  = errors.New("unknown player token for this game")
+)
+
+// Hand out the tokens of both sides of the game
+func (server *Server) addPlayers(id string) playerTokens {
+    tokens := playerTokens{newToken(), newToken()}
+    server.mutex.Lock()
+    defer server.mutex.Unlock()
+    server.players[tokens.White] = player{id, whiteTeam}
+    server.players[tokens.Black] = player{id, blackTeam}
+    return tokens
+}
+
+// Forget the tokens of the game once it's removed
+func (server *Server) removePlayers(id string) {
+    server.mutex.Lock()
+    defer server.mutex.Unlock()
+    for token, player := range server.players {
+        if player.gameID == id {
+            delete(server.players, token)
+        }
+    }
+}
+
+// Get the side the token of the request plays in the game, false if there is no token.
+// A token of another game or an unknown one is an error.
+func (server *Server) getPlayer(request *http.Request, id string) (game.Team, bool, error) {
+    token := request.URL.Query().Get("token")
+    if token == "" {
+        return whiteTeam, false, nil
+    }
+    server.mutex.Lock()
+    player, found := server.players[token]
+    server.mutex.Unlock()
+    if !found || player.gameID != id {
+        return whiteTeam, false, errUnknownToken
+    }
+    return player.team, true, nil
+}
+
+// Browsers send the Origin of the page opening a WebSocket. Pages of the server itself and the origins allowed with
+// AllowOrigins() may connect, clients that send no Origin (not a browser) always may.
+func (server *Server) isOriginAllowed(request *http.Request) bool {
+    origin := request.Header.Get("Origin")
+    if origin == "" {
+        return true
+    }
+    if parsed, err := url.Parse(origin); err == nil && parsed.Host == request.Host {
+        return true
+    }
+    server.mutex.Lock()
+    defer server.mutex.Unlock()
+    return server.allowedOrigins[origin]
+}
+
+// Allow web pages of the origins (e.g. "https://chess.example.com") to open WebSockets to the server
+func (server *Server) AllowOrigins(origins ...string) {
+    server.mutex.Lock()
+    defer server.mutex.Unlock()
+    for _, origin := range origins {
+        server.allowedOrigins[origin] = true
+    }
+}
+
+func newToken() string {
+    bytes := make([]byte, 16)
+    if _, err := rand.Read(bytes); err != nil {
+        panic("no random source for player tokens: " + err.Error())
+    }
+    return hex.EncodeToString(bytes)
+}
@@ -0,0 +1,470 @@
+package server
+
+import (
+    "encoding/json"
+    "errors"
+    "github.com/dilyar85/chess/game"
+    "net/http"
+    "strings"
+    "sync"
+    "time"
+)
+
+// Server hosts games over an HTTP JSON API:
+//
+//   POST /games               create a game, body {"fen": "...", "time": "5m", "increment": "2s", "delay": "0s"} (all optional)
+//   GET  /games               list the games
+//   GET  /games/{id}          get the state of a game
+//   POST /games/{id}/moves    make a move, body {"move": "e2 e4"}
+//   POST /games/{id}/undo     take back the last move
+//   POST /games/{id}/resign   resign, body {"side": "white"} (optional, must be the player's side)
+//   GET  /games/{id}/pgn      get the game in PGN (text/plain)
+//   GET  /games/{id}/ws       stream the game over WebSocket (see stream.go)
+//
+// The answer to POST /games has the tokens of both players, the POST requests on a game need one (see players.go).
+// Every request on a game locks that game only, so games are played independently of each other.
+// Finished games are removed an hour after the server notices their end, so their PGN can still be downloaded.
+type Server struct {
+    sessions       *game.SessionManager
+    mutex          sync.Mutex //guards streams, players, allowedOrigins and finished
+    streams        map[string]*gameStream
+    players        map[string]player //player token to the game and side it plays
+    allowedOrigins map[string]bool
+    finished       map[string]time.Time //game id to when its end was noticed
+    clockInterval  time.Duration        //time between clock events of live games
+    retention      time.Duration        //time finished games are kept
+}
+
+const finishedGameRetention = time.Hour
+
+func New() *Server {
+    return &Server{
+        sessions:       game.NewSessionManager(),
+        streams:        make(map[string]*gameStream),
+        players:        make(map[string]player),
+        allowedOrigins: make(map[string]bool),
+        finished:       make(map[string]time.Time),
+        clockInterval:  clockEventInterval,
+        retention:      finishedGameRetention,
+    }
+}
+
+// MARK: JSON messages
+type createRequest struct {
+    FEN       string `json:"fen"`
+    Time      string `json:"time"`
+    Increment string `json:"increment"`
+    Delay     string `json:"delay"`
+}
+
+// Body of a player action, over HTTP the action is taken from the path
+type actionRequest struct {
+    Action string `json:"action"` //"move", "undo" or "resign", WebSocket messages only
+    Move   string `json:"move"`   //e.g. "e2 e4"
+    Side   string `json:"side"`   //side that resigns, the player's own side if empty
+}
+
+type createResponse struct {
+    gameState
+    Tokens playerTokens `json:"tokens"`
+}
+
+type gameState struct {
+    ID         string        `json:"id"`
+    FEN        string        `json:"fen"`
+    Board      []string      `json:"board"` //ranks 8 to 1, FEN letters and "." for empty squares
+    Turn       string        `json:"turn"`
+    InCheck    bool          `json:"inCheck"`
+    LegalMoves []string      `json:"legalMoves"`
+    History    []string      `json:"history"`
+    LastMove   []string      `json:"lastMove"`
+    Opening    *openingState `json:"opening"` //null until a known opening is reached
+    Outcome    *outcomeState `json:"outcome"` //null while the game is in progress
+    Clocks     *clockState   `json:"clocks"`  //null when playing without clocks
+}
+
+type outcomeState struct {
+    Result string `json:"result"` //"1-0", "0-1", "1/2-1/2" or "*"
+    Winner string `json:"winner"` //"white", "black" or "" for draws
+    Reason string `json:"reason"`
+}
+
+type openingState struct {
+    ECO  string `json:"eco"`
+    Name string `json:"name"`
+}
+
+type clockState struct {
+    White int64 `json:"white"` //remaining milliseconds
+    Black int64 `json:"black"`
+}
+
+type gameSummary struct {
+    ID      string `json:"id"`
+    FEN     string `json:"fen"`
+    Turn    string `json:"turn"`
+    Moves   int    `json:"moves"`
+    Result  string `json:"result"` //"" while the game is in progress
+    Created string `json:"created"`
+}
+
+type errorResponse struct {
+    Error string `json:"error"`
+}
+
+// MARK: Routing
+var endpointActions = map[string]string{"moves": "move", "undo": "undo", "resign": "resign"}
+
+func (server *Server) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+    path := strings.Trim(request.URL.Path, "/")
+    parts := strings.Split(path, "/")
+    if parts[0] != "games" {
+        writeError(writer, http.StatusNotFound, "not found")
+        return
+    }
+
+    switch len(parts) {
+    case 1:
+        switch request.Method {
+        case http.MethodGet:
+            server.listGames(writer)
+        case http.MethodPost:
+            server.createGame(writer, request)
+        default:
+            methodNotAllowed(writer, "GET, POST")
+        }
+
+    case 2:
+        if request.Method != http.MethodGet {
+            methodNotAllowed(writer, "GET")
+            return
+        }
+        server.withGame(writer, parts[1], "", func(chessGame *game.ChessGame) (int, error) {
+            return http.StatusOK, nil
+        })
+
+    case 3:
+        if parts[2] == "ws" {
+            if request.Method != http.MethodGet {
+                methodNotAllowed(writer, "GET")
+                return
+            }
+            server.streamGame(writer, request, parts[1])
+            return
+        }
+        if parts[2] == "pgn" {
+            if request.Method != http.MethodGet {
+                methodNotAllowed(writer, "GET")
+                return
+            }
+            server.writePGN(writer, parts[1])
+            return
+        }
+
+        if request.Method != http.MethodPost {
+            methodNotAllowed(writer, "POST")
+            return
+        }
+        name, found := endpointActions[parts[2]]
+        if !found {
+            writeError(writer, http.StatusNotFound, "not found")
+            return
+        }
+        if _, found := server.sessions.Get(parts[1]); !found {
+            writeError(writer, http.StatusNotFound, "no game with id "+parts[1])
+            return
+        }
+        team, isPlayer, err := server.getPlayer(request, parts[1])
+        if err != nil {
+            writeError(writer, http.StatusForbidden, err.Error())
+            return
+        }
+        if !isPlayer {
+            writeError(writer, http.StatusUnauthorized, errTokenRequired.Error())
+            return
+        }
+        var body actionRequest
+        if name != "undo" && !readJSON(writer, request, &body) {
+            return
+        }
+        action, _ := getAction(name, body, team)
+        server.withGame(writer, parts[1], name, action)
+
+    default:
+        writeError(writer, http.StatusNotFound, "not found")
+    }
+}
+
+// MARK: Handlers
+func (server *Server) createGame(writer http.ResponseWriter, request *http.Request) {
+    server.removeFinishedGames(time.Now())
+    var body createRequest
+    if request.ContentLength != 0 && !readJSON(writer, request, &body) {
+        return
+    }
+
+    fen := body.FEN
+    if fen == "" {
+        fen = game.StartFEN
+    }
+    chessGame, err := game.NewFromFEN(fen)
+    if err != nil {
+        writeError(writer, http.StatusBadRequest, "invalid FEN: "+err.Error())
+        return
+    }
+
+    if body.Time != "" {
+        control, err := parseTimeControl(body)
+        if err != nil {
+            writeError(writer, http.StatusBadRequest, err.Error())
+            return
+        }
+        chessGame.SetTimeControl(control)
+        chessGame.StartClocks()
+    }
+
+    session := server.sessions.Create(chessGame)
+    response := createResponse{Tokens: server.addPlayers(session.ID())}
+    session.Do(func(chessGame *game.ChessGame) error {
+        response.gameState = getState(session.ID(), chessGame)
+        return nil
+    })
+    writeJSON(writer, http.StatusCreated, response)
+}
+
+func (server *Server) listGames(writer http.ResponseWriter) {
+    server.removeFinishedGames(time.Now())
+    sessions := server.sessions.List()
+    summaries := make([]gameSummary, 0, len(sessions))
+    for _, session := range sessions {
+        summary := gameSummary{ID: session.ID(), Created: session.Created().UTC().Format(time.RFC3339)}
+        session.Do(func(chessGame *game.ChessGame) error {
+            summary.FEN = chessGame.FEN()
+            summary.Turn = chessGame.SideToMove().String()
+            summary.Moves = len(chessGame.History())
+            if outcome := chessGame.Outcome(); outcome != nil {
+                summary.Result = outcome.Result()
+            }
+            return nil
+        })
+        summaries = append(summaries, summary)
+    }
+    writeJSON(writer, http.StatusOK, summaries)
+}
+
+func (server *Server) writePGN(writer http.ResponseWriter, id string) {
+    session, found := server.sessions.Get(id)
+    if !found {
+        writeError(writer, http.StatusNotFound, "no game with id "+id)
+        return
+    }
+    var pgn string
+    session.Do(func(chessGame *game.ChessGame) error {
+        chessGame.Outcome() //ends the game if a flag has fallen
+        pgn = chessGame.PGN()
+        return nil
+    })
+    writer.Header().Set("Content-Type", "text/plain; charset=utf-8")
+    writer.Write([]byte(pgn))
+}
+
+// Stop hosting the games that ended more than the retention time ago, with their player tokens.
+// A game's end is noticed by the first sweep after it, which runs when games are created or listed.
+func (server *Server) removeFinishedGames(now time.Time) {
+    for _, session := range server.sessions.List() {
+        ended := false
+        session.Do(func(chessGame *game.ChessGame) error {
+            ended = chessGame.Outcome() != nil
+            return nil
+        })
+        if !ended {
+            continue
+        }
+
+        id := session.ID()
+        server.mutex.Lock()
+        endTime, noticed := server.finished[id]
+        if !noticed {
+            endTime = now
+            server.finished[id] = now
+        }
+        expired := now.Sub(endTime) >= server.retention
+        if expired {
+            delete(server.finished, id)
+        }
+        server.mutex.Unlock()
+
+        if expired {
+            server.sessions.Remove(id)
+            server.removePlayers(id)
+        }
+    }
+}
+
+// Run the action on the locked game and write its state, or the error of the action with the status returned.
+// If the action succeeds and event is not "", the event is sent to everyone watching the game.
+func (server *Server) withGame(writer http.ResponseWriter, id string, event string, action func(chessGame *game.ChessGame) (int, error)) {
+    session, found := server.sessions.Get(id)
+    if !found {
+        writeError(writer, http.StatusNotFound, "no game with id "+id)
+        return
+    }
+    var status int
+    var state gameState
+    err := session.Do(func(chessGame *game.ChessGame) error {
+        var err error
+        if status, err = action(chessGame); err != nil {
+            return err
+        }
+        if stream := server.findStream(id); stream != nil && event != "" {
+            stream.broadcast(event, chessGame)
+        }
+        state = getState(id, chessGame)
+        return nil
+    })
+    if err != nil {
+        writeError(writer, status, err.Error())
+        return
+    }
+    writeJSON(writer, http.StatusOK, state)
+}
+
+// Get the change a player action ("move", "undo" or "resign") of the team makes to a game.
+// The change returns the status to answer with if it fails.
+func getAction(name string, body actionRequest, team game.Team) (func(chessGame *game.ChessGame) (int, error), bool) {
+    switch name {
+    case "move":
+        return func(chessGame *game.ChessGame) (int, error) {
+            if chessGame.Outcome() == nil && chessGame.SideToMove() != team {
+                return http.StatusForbidden, errors.New("it's not your turn")
+            }
+            _, err := chessGame.Move(body.Move)
+            return http.StatusBadRequest, err
+        }, true
+    case "undo":
+        return func(chessGame *game.ChessGame) (int, error) {
+            if chessGame.Outcome() == nil && len(chessGame.History()) > 0 && chessGame.SideToMove() == team {
+                return http.StatusForbidden, errors.New("only the player who made the last move can take it back")
+            }
+            return http.StatusConflict, chessGame.Undo()
+        }, true
+    case "resign":
+        return func(chessGame *game.ChessGame) (int, error) {
+            if body.Side != "" {
+                side, err := game.ParseTeam(body.Side)
+                if err != nil {
+                    return http.StatusBadRequest, err
+                }
+                if side != team {
+                    return http.StatusForbidden, errors.New("players can only resign their own side")
+                }
+            }
+            return http.StatusConflict, chessGame.Resign(team)
+        }, true
+    }
+    return nil, false
+}
+
+// Get the state of the game, the caller must hold its lock
+func getState(id string, chessGame *game.ChessGame) gameState {
+    position := chessGame.Position()
+    fen := position.FEN()
+    state := gameState{
+        ID:       id,
+        FEN:      fen,
+        Board:    expandPlacement(strings.Fields(fen)[0]),
+        Turn:     position.SideToMove().String(),
+        InCheck:  position.InCheck(),
+        History:  chessGame.History(),
+        LastMove: chessGame.LastMove(),
+    }
+
+    if opening, found := chessGame.Opening(); found {
+        state.Opening = &openingState{opening.ECO, opening.Name}
+    }
+    if outcome := chessGame.Outcome(); outcome != nil {
+        state.Outcome = &outcomeState{outcome.Result(), outcome.Winner().String(), outcome.Reason.String()}
+    }
+    state.LegalMoves = chessGame.LegalMoves() //after Outcome(), which ends the game if a flag has fallen
+
+    if whiteTime, blackTime, hasClocks := chessGame.RemainingTimes(); hasClocks {
+        state.Clocks = &clockState{whiteTime.Milliseconds(), blackTime.Milliseconds()}
+    }
+
+    //Encode empty lists as [] instead of null
+    if state.LegalMoves == nil {
+        state.LegalMoves = []string{}
+    }
+    if state.History == nil {
+        state.History = []string{}
+    }
+    if state.LastMove == nil {
+        state.LastMove = []string{}
+    }
+    return state
+}
+
+// MARK: Helpers
+func parseTimeControl(body createRequest) (game.TimeControl, error) {
+    var control game.TimeControl
+    var err error
+    if control.Base, err = time.ParseDuration(body.Time); err != nil || control.Base <= 0 {
+        return control, errors.New("invalid time: " + body.Time)
+    }
+    if body.Increment != "" {
+        if control.Increment, err = time.ParseDuration(body.Increment); err != nil || control.Increment < 0 {
+            return control, errors.New("invalid increment: " + body.Increment)
+        }
+    }
+    if body.Delay != "" {
+        if control.Delay, err = time.ParseDuration(body.Delay); err != nil || control.Delay < 0 {
+            return control, errors.New("invalid delay: " + body.Delay)
+        }
+    }
+    return control, nil
+}
+
+// Expand a FEN piece placement to one string of 8 characters per rank, "." for empty squares.
+// Crazyhouse pockets after the placement are left out, they are in the FEN.
+func expandPlacement(placement string) []string {
+    if start := strings.Index(placement, "["); start >= 0 {
+        placement = placement[:start]
+    }
+    var ranks []string
+    for _, rank := range strings.Split(placement, "/") {
+        expanded := ""
+        for _, char := range rank {
+            if char >= '1' && char <= '8' {
+                expanded += strings.Repeat(".", int(char-'0'))
+            } else {
+                expanded += string(char)
+            }
+        }
+        ranks = append(ranks, expanded)
+    }
+    return ranks
+}
+
+func readJSON(writer http.ResponseWriter, request *http.Request, value interface{}) bool {
+    decoder := json.NewDecoder(http.MaxBytesReader(writer, request.Body, 1<<16))
+    if err := decoder.Decode(value); err != nil {
+        writeError(writer, http.StatusBadRequest, "invalid JSON: "+err.Error())
+        return false
+    }
+    return true
+}
+
+func writeJSON(writer http.ResponseWriter, status int, value interface{}) {
+    writer.Header().Set("Content-Type", "application/json")
+    writer.WriteHeader(status)
+    json.NewEncoder(writer).Encode(value)
+}
+
+func writeError(writer http.ResponseWriter, status int, message string) {
+    writeJSON(writer, status, errorResponse{message})
+}
+
+func methodNotAllowed(writer http.ResponseWriter, allowed string) {
+    writer.Header().Set("Allow", allowed)
+    writeError(writer, http.StatusMethodNotAllowed, "method not allowed")
+}
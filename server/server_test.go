@@ -0,0 +1,98 @@
+package server
+
+import (
+    "encoding/json"
+    "net/http"
+    "net/http/httptest"
+    "strings"
+    "testing"
+)
+
+func TestActionsNeedPlayerToken(t *testing.T) {
+    httpServer := httptest.NewServer(New())
+    defer httpServer.Close()
+    created := createTestGame(t, httpServer, "")
+    other := createTestGame(t, httpServer, "")
+    game := "/games/" + created.ID
+
+    if created.Tokens.White == "" || created.Tokens.Black == "" || created.Tokens.White == created.Tokens.Black {
+        t.Fatalf("unexpected tokens: %+v", created.Tokens)
+    }
+
+    tests := []struct {
+        name, path, token, body string
+        status                  int
+    }{
+        {"no token", game + "/moves", "", `{"move": "e2 e4"}`, http.StatusUnauthorized},
+        {"token of another game", game + "/moves", other.Tokens.White, `{"move": "e2 e4"}`, http.StatusForbidden},
+        {"out of turn", game + "/moves", created.Tokens.Black, `{"move": "e2 e4"}`, http.StatusForbidden},
+        {"move", game + "/moves", created.Tokens.White, `{"move": "e2 e4"}`, http.StatusOK},
+        {"take back the opponent's move", game + "/undo", created.Tokens.Black, "", http.StatusForbidden},
+        {"take back the own move", game + "/undo", created.Tokens.White, "", http.StatusOK},
+        {"resign the other side", game + "/resign", created.Tokens.White, `{"side": "black"}`, http.StatusForbidden},
+        {"resign", game + "/resign", created.Tokens.Black, `{}`, http.StatusOK},
+        {"move after the game", game + "/moves", created.Tokens.White, `{"move": "e2 e4"}`, http.StatusBadRequest},
+    }
+    for _, test := range tests {
+        if status := postAction(t, httpServer, test.path, test.token, test.body); status != test.status {
+            t.Errorf("%s: status %d, expected %d", test.name, status, test.status)
+        }
+    }
+}
+
+func TestMalformedMovesAreIllegal(t *testing.T) {
+    httpServer := httptest.NewServer(New())
+    defer httpServer.Close()
+    created := createTestGame(t, httpServer, "")
+    url := httpServer.URL + "/games/" + created.ID + "/moves?token=" + created.Tokens.White
+
+    for _, move := range []string{"e2 e", "e e4", "e2", "", "e2 e44", "i2 i4", "e2  e4", "P@"} {
+        body, _ := json.Marshal(map[string]string{"move": move})
+        response, err := http.Post(url, "application/json", strings.NewReader(string(body)))
+        if err != nil {
+            t.Fatal(err)
+        }
+        var answer errorResponse
+        err = json.NewDecoder(response.Body).Decode(&answer)
+        response.Body.Close()
+        if err != nil || response.StatusCode != http.StatusBadRequest || answer.Error != "Illegal move! Please enter again." {
+            t.Errorf("%q: status %d, error %q", move, response.StatusCode, answer.Error)
+        }
+    }
+
+    //The game goes on after the rejected moves
+    if status := postAction(t, httpServer, "/games/"+created.ID+"/moves", created.Tokens.White, `{"move": "e2 e4"}`); status != http.StatusOK {
+        t.Errorf("move after the rejected moves: status %d", status)
+    }
+}
+
+func TestFinishedGamesAreRemoved(t *testing.T) {
+    server := New()
+    server.retention = 0
+    httpServer := httptest.NewServer(server)
+    defer httpServer.Close()
+    finished := createTestGame(t, httpServer, "")
+    playing := createTestGame(t, httpServer, "")
+
+    if status := postAction(t, httpServer, "/games/"+finished.ID+"/resign", finished.Tokens.White, `{}`); status != http.StatusOK {
+        t.Fatalf("resign: %d", status)
+    }
+    createTestGame(t, httpServer, "") //removes the finished game
+
+    for id, expected := range map[string]int{finished.ID: http.StatusNotFound, playing.ID: http.StatusOK} {
+        response, err := http.Get(httpServer.URL + "/games/" + id)
+        if err != nil {
+            t.Fatal(err)
+        }
+        response.Body.Close()
+        if response.StatusCode != expected {
+            t.Errorf("game %s: status %d, expected %d", id, response.StatusCode, expected)
+        }
+    }
+
+    server.mutex.Lock()
+    defer server.mutex.Unlock()
+    if _, found := server.players[finished.Tokens.White]; found || len(server.players) != 4 {
+        t.Errorf("tokens of the removed game kept: %d tokens", len(server.players))
+    }
+}
@@ -0,0 +1,252 @@
+package server
+
+import (
+    "encoding/json"
+    "github.com/dilyar85/chess/game"
+    "net/http"
+    "time"
+)
+
+// Live games over WebSocket: GET /games/{id}/ws, or GET /games/{id}/ws?token=... for a player (see players.go)
+//
+// On connect (and so on every reconnect) the client gets a "state" event with the full game state.
+// After that everyone connected to the game gets an event for every change, made over HTTP or WebSocket:
+//
+//   {"type": "move" | "undo" | "resign", "state": {...}}   state after the change
+//   {"type": "outcome", "state": {...}}                    game ended on time
+//   {"type": "clock", "clocks": {"white": 1000, "black": 1000}}   every second while the clocks run
+//
+// Players can send {"action": "move", "move": "e2 e4"}, {"action": "undo"} or {"action": "resign"} for their side,
+// a failing action is answered with {"type": "error", "error": "..."} to the player only.
+// Spectators (clients without a token) can only watch.
+// Connections from web pages of other origins are refused, unless the origin is allowed with AllowOrigins().
+// A stream is removed when its last client leaves.
+
+const (
+    clockEventInterval = time.Second
+    subscriberBuffer   = 32 //events queued for a slow client before it is dropped
+)
+
+type streamEvent struct {
+    Type   string      `json:"type"`
+    State  *gameState  `json:"state,omitempty"`
+    Clocks *clockState `json:"clocks,omitempty"`
+    Error  string      `json:"error,omitempty"`
+}
+
+// Players and spectators of one game connected over WebSocket.
+// Its fields are guarded by the lock of the game's session, like the game itself.
+type gameStream struct {
+    id          string
+    server      *Server
+    session     *game.Session
+    subscribers map[*subscriber]bool
+    ticking     bool //clock events are being sent
+    finished    bool //the outcome has been sent to the subscribers
+}
+
+// One WebSocket client of a game, events are written by its own goroutine so a slow client cannot hold the game lock
+type subscriber struct {
+    ws     *wsConn
+    player bool
+    team   game.Team //side of a player
+    events chan []byte
+}
+
+// Get the stream of the game, created on first use. The caller must hold the game lock,
+// so that streams are only created and removed while nothing is sent on them.
+func (server *Server) getStream(id string, session *game.Session) *gameStream {
+    server.mutex.Lock()
+    defer server.mutex.Unlock()
+    stream := server.streams[id]
+    if stream == nil {
+        stream = &gameStream{id: id, server: server, session: session, subscribers: make(map[*subscriber]bool)}
+        server.streams[id] = stream
+    }
+    return stream
+}
+
+// Get the stream of the game, nil if nobody is watching. The caller must hold the game lock.
+func (server *Server) findStream(id string) *gameStream {
+    server.mutex.Lock()
+    defer server.mutex.Unlock()
+    return server.streams[id]
+}
+
+func (server *Server) streamGame(writer http.ResponseWriter, request *http.Request, id string) {
+    if !server.isOriginAllowed(request) {
+        writeError(writer, http.StatusForbidden, "origin not allowed: "+request.Header.Get("Origin"))
+        return
+    }
+    session, found := server.sessions.Get(id)
+    if !found {
+        writeError(writer, http.StatusNotFound, "no game with id "+id)
+        return
+    }
+    team, isPlayer, err := server.getPlayer(request, id)
+    if err != nil {
+        writeError(writer, http.StatusForbidden, err.Error())
+        return
+    }
+
+    ws, err := upgradeWebSocket(writer, request)
+    if err != nil {
+        writeError(writer, http.StatusBadRequest, err.Error())
+        return
+    }
+    client := &subscriber{ws: ws, player: isPlayer, team: team, events: make(chan []byte, subscriberBuffer)}
+    go client.writeEvents()
+
+    var stream *gameStream
+    session.Do(func(chessGame *game.ChessGame) error {
+        stream = server.getStream(id, session)
+        state := getState(id, chessGame)
+        if state.Outcome != nil {
+            stream.finished = true
+        }
+        client.send(streamEvent{Type: "state", State: &state})
+        stream.subscribers[client] = true
+        stream.startClockEvents(chessGame)
+        return nil
+    })
+
+    client.readActions(stream)
+
+    stream.session.Do(func(chessGame *game.ChessGame) error {
+        stream.unsubscribe(client)
+        return nil
+    })
+}
+
+// Send the event with the state of the game to all subscribers, the caller must hold the game lock
+func (stream *gameStream) broadcast(eventType string, chessGame *game.ChessGame) {
+    state := getState(stream.id, chessGame)
+    if state.Outcome != nil {
+        stream.finished = true
+    }
+    stream.sendAll(streamEvent{Type: eventType, State: &state})
+}
+
+// The caller must hold the game lock
+func (stream *gameStream) sendAll(event streamEvent) {
+    for client := range stream.subscribers {
+        if !client.send(event) {
+            stream.unsubscribe(client)
+        }
+    }
+}
+
+// Send the remaining times every second while anyone is watching, and the outcome if a flag falls.
+// The caller must hold the game lock.
+func (stream *gameStream) startClockEvents(chessGame *game.ChessGame) {
+    if _, _, hasClocks := chessGame.RemainingTimes(); !hasClocks || stream.ticking || stream.finished {
+        return
+    }
+    stream.ticking = true
+
+    go func() {
+        ticker := time.NewTicker(stream.server.clockInterval)
+        defer ticker.Stop()
+        for range ticker.C {
+            stopped := false
+            stream.session.Do(func(chessGame *game.ChessGame) error {
+                if len(stream.subscribers) > 0 && !stream.finished && chessGame.Outcome() != nil {
+                    stream.broadcast("outcome", chessGame) //flag fell
+                }
+                if len(stream.subscribers) == 0 || stream.finished {
+                    stream.ticking = false
+                    stopped = true
+                    return nil
+                }
+
+                whiteTime, blackTime, _ := chessGame.RemainingTimes()
+                stream.sendAll(streamEvent{Type: "clock", Clocks: &clockState{whiteTime.Milliseconds(), blackTime.Milliseconds()}})
+                return nil
+            })
+            if stopped {
+                return
+            }
+        }
+    }()
+}
+
+// Remove the client, and the stream when it was the last one. The caller must hold the game lock.
+func (stream *gameStream) unsubscribe(client *subscriber) {
+    if stream.subscribers[client] {
+        delete(stream.subscribers, client)
+        close(client.events)
+    }
+    if len(stream.subscribers) == 0 {
+        stream.server.mutex.Lock()
+        if stream.server.streams[stream.id] == stream {
+            delete(stream.server.streams, stream.id)
+        }
+        stream.server.mutex.Unlock()
+    }
+}
+
+// Queue the event, return false if the client is too slow to keep up
+func (client *subscriber) send(event streamEvent) bool {
+    message, _ := json.Marshal(event)
+    select {
+    case client.events <- message:
+        return true
+    default:
+        return false
+    }
+}
+
+func (client *subscriber) writeEvents() {
+    for message := range client.events {
+        if err := client.ws.writeMessage(message); err != nil {
+            break
+        }
+    }
+    client.ws.close() //also ends readActions()
+}
+
+// Run the actions of a player until the connection is closed
+func (client *subscriber) readActions(stream *gameStream) {
+    for {
+        message, err := client.ws.readMessage()
+        if err != nil {
+            return
+        }
+
+        var request actionRequest
+        if err := json.Unmarshal(message, &request); err != nil {
+            client.sendError(stream, "invalid JSON: "+err.Error())
+            continue
+        }
+        if !client.player {
+            client.sendError(stream, "spectators cannot make changes")
+            continue
+        }
+        action, found := getAction(request.Action, request, client.team)
+        if !found {
+            client.sendError(stream, "unknown action: "+request.Action)
+            continue
+        }
+
+        err = stream.session.Do(func(chessGame *game.ChessGame) error {
+            if _, err := action(chessGame); err != nil {
+                return err
+            }
+            stream.broadcast(request.Action, chessGame)
+            return nil
+        })
+        if err != nil {
+            client.sendError(stream, err.Error())
+        }
+    }
+}
+
+// Send an error to the client only, unless it has been dropped meanwhile
+func (client *subscriber) sendError(stream *gameStream, message string) {
+    stream.session.Do(func(chessGame *game.ChessGame) error {
+        if stream.subscribers[client] {
+            client.send(streamEvent{Type: "error", Error: message})
+        }
+        return nil
+    })
+}
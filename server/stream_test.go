@@ -0,0 +1,281 @@
+package server
+
+import (
+    "bufio"
+    "encoding/binary"
+    "encoding/json"
+    "io"
+    "net"
+    "net/http"
+    "net/http/httptest"
+    "strings"
+    "testing"
+    "time"
+)
+
+// Key and accept value of the handshake example in RFC 6455
+const (
+    testWebSocketKey    = "dGhlIHNhbXBsZSBub25jZQ=="
+    testWebSocketAccept = "s3pPLMBiTxaQ9kYGzzhZRbK+xOo="
+)
+
+// MARK: testClient (WebSocket client written from the RFC, independent of wsConn)
+type testClient struct {
+    t      *testing.T
+    conn   net.Conn
+    reader *bufio.Reader
+}
+
+// Open a WebSocket to the path, the client is nil if the server doesn't switch protocols
+func dialWebSocket(t *testing.T, httpServer *httptest.Server, path string, header string) (*testClient, *http.Response) {
+    conn, err := net.Dial("tcp", httpServer.Listener.Addr().String())
+    if err != nil {
+        t.Fatal(err)
+    }
+    request := "GET " + path + " HTTP/1.1\r\n" +
+        "Host: " + httpServer.Listener.Addr().String() + "\r\n" +
+        "Upgrade: websocket\r\n" +
+        "Connection: Upgrade\r\n" +
+        "Sec-WebSocket-Key: " + testWebSocketKey + "\r\n" +
+        "Sec-WebSocket-Version: 13\r\n" + header + "\r\n"
+    if _, err := conn.Write([]byte(request)); err != nil {
+        t.Fatal(err)
+    }
+
+    reader := bufio.NewReader(conn)
+    response, err := http.ReadResponse(reader, nil)
+    if err != nil {
+        t.Fatal(err)
+    }
+    if response.StatusCode != http.StatusSwitchingProtocols {
+        conn.Close()
+        return nil, response
+    }
+    client := &testClient{t, conn, reader}
+    t.Cleanup(client.close)
+    return client, response
+}
+
+// Send a text message in a masked frame
+func (client *testClient) send(message string) {
+    mask := [4]byte{0x12, 0x34, 0x56, 0x78}
+    frame := []byte{0x81, 0x80 | byte(len(message))} //messages of the tests are shorter than 126 bytes
+    frame = append(frame, mask[:]...)
+    for i := 0; i < len(message); i++ {
+        frame = append(frame, message[i]^mask[i%4])
+    }
+    if _, err := client.conn.Write(frame); err != nil {
+        client.t.Fatal(err)
+    }
+}
+
+// Read the next event, skipping clock events unless the type asked for is "clock"
+func (client *testClient) next(eventType string) streamEvent {
+    client.t.Helper()
+    client.conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+    for {
+        var header [2]byte
+        if _, err := io.ReadFull(client.reader, header[:]); err != nil {
+            client.t.Fatalf("waiting for %s: %v", eventType, err)
+        }
+        if header[1]&0x80 != 0 {
+            client.t.Fatal("server frames must not be masked")
+        }
+        length := uint64(header[1] & 0x7F)
+        switch length {
+        case 126:
+            var extended [2]byte
+            io.ReadFull(client.reader, extended[:])
+            length = uint64(binary.BigEndian.Uint16(extended[:]))
+        case 127:
+            var extended [8]byte
+            io.ReadFull(client.reader, extended[:])
+            length = binary.BigEndian.Uint64(extended[:])
+        }
+        payload := make([]byte, length)
+        if _, err := io.ReadFull(client.reader, payload); err != nil {
+            client.t.Fatal(err)
+        }
+
+        var event streamEvent
+        if err := json.Unmarshal(payload, &event); err != nil {
+            client.t.Fatalf("invalid event %q: %v", payload, err)
+        }
+        if event.Type == "clock" && eventType != "clock" {
+            continue
+        }
+        if event.Type != eventType {
+            client.t.Fatalf("expected a %s event, got %s", eventType, payload)
+        }
+        return event
+    }
+}
+
+func (client *testClient) close() {
+    client.conn.Close()
+}
+
+// MARK: HTTP helpers
+func createTestGame(t *testing.T, httpServer *httptest.Server, body string) createResponse {
+    response, err := http.Post(httpServer.URL+"/games", "application/json", strings.NewReader(body))
+    if err != nil {
+        t.Fatal(err)
+    }
+    defer response.Body.Close()
+    var created createResponse
+    if err := json.NewDecoder(response.Body).Decode(&created); err != nil || response.StatusCode != http.StatusCreated {
+        t.Fatalf("create game: %d %v", response.StatusCode, err)
+    }
+    return created
+}
+
+// Post the action and return the status of the answer
+func postAction(t *testing.T, httpServer *httptest.Server, path, token, body string) int {
+    url := httpServer.URL + path
+    if token != "" {
+        url += "?token=" + token
+    }
+    response, err := http.Post(url, "application/json", strings.NewReader(body))
+    if err != nil {
+        t.Fatal(err)
+    }
+    response.Body.Close()
+    return response.StatusCode
+}
+
+// MARK: Tests
+func TestWebSocketHandshake(t *testing.T) {
+    httpServer := httptest.NewServer(New())
+    defer httpServer.Close()
+    created := createTestGame(t, httpServer, "")
+
+    client, response := dialWebSocket(t, httpServer, "/games/"+created.ID+"/ws", "")
+    if client == nil {
+        t.Fatalf("handshake refused: %d", response.StatusCode)
+    }
+    if accept := response.Header.Get("Sec-WebSocket-Accept"); accept != testWebSocketAccept {
+        t.Errorf("Sec-WebSocket-Accept = %q, expected %q", accept, testWebSocketAccept)
+    }
+
+    //Full state on connect
+    state := client.next("state").State
+    if state == nil || state.ID != created.ID || state.FEN != created.FEN || len(state.LegalMoves) != 20 {
+        t.Errorf("unexpected state on connect: %+v", state)
+    }
+
+    refused := []struct {
+        name, path, header string
+        status             int
+    }{
+        {"unknown game", "/games/404/ws", "", http.StatusNotFound},
+        {"unknown token", "/games/" + created.ID + "/ws?token=nope", "", http.StatusForbidden},
+        {"other site", "/games/" + created.ID + "/ws", "Origin: https://evil.example.com\r\n", http.StatusForbidden},
+    }
+    for _, test := range refused {
+        if client, response := dialWebSocket(t, httpServer, test.path, test.header); client != nil || response.StatusCode != test.status {
+            t.Errorf("%s: status %d, expected %d", test.name, response.StatusCode, test.status)
+        }
+    }
+}
+
+func TestWebSocketAllowedOrigin(t *testing.T) {
+    server := New()
+    server.AllowOrigins("https://chess.example.com")
+    httpServer := httptest.NewServer(server)
+    defer httpServer.Close()
+    created := createTestGame(t, httpServer, "")
+
+    for _, origin := range []string{"https://chess.example.com", httpServer.URL} {
+        if client, response := dialWebSocket(t, httpServer, "/games/"+created.ID+"/ws", "Origin: "+origin+"\r\n"); client == nil {
+            t.Errorf("origin %s refused: %d", origin, response.StatusCode)
+        }
+    }
+}
+
+func TestStreamBroadcastsToSpectators(t *testing.T) {
+    httpServer := httptest.NewServer(New())
+    defer httpServer.Close()
+    created := createTestGame(t, httpServer, "")
+    path := "/games/" + created.ID + "/ws"
+
+    spectator, _ := dialWebSocket(t, httpServer, path, "")
+    spectator.next("state")
+    white, _ := dialWebSocket(t, httpServer, path+"?token="+created.Tokens.White, "")
+    white.next("state")
+
+    //A player's move over WebSocket reaches everyone
+    white.send(`{"action": "move", "move": "e2 e4"}`)
+    for _, client := range []*testClient{spectator, white} {
+        state := client.next("move").State
+        if strings.Join(state.History, " ") != "e4" || state.Turn != "black" {
+            t.Errorf("unexpected state after the move: %+v", state)
+        }
+    }
+
+    //Spectators cannot make moves, players only move their own side
+    spectator.send(`{"action": "move", "move": "e7 e5"}`)
+    if event := spectator.next("error"); !strings.Contains(event.Error, "spectators") {
+        t.Errorf("unexpected error for the spectator: %s", event.Error)
+    }
+    white.send(`{"action": "move", "move": "e7 e5"}`)
+    if event := white.next("error"); !strings.Contains(event.Error, "turn") {
+        t.Errorf("unexpected error for the player out of turn: %s", event.Error)
+    }
+
+    //Moves over HTTP are streamed too
+    if status := postAction(t, httpServer, "/games/"+created.ID+"/moves", created.Tokens.Black, `{"move": "e7 e5"}`); status != http.StatusOK {
+        t.Fatalf("move over HTTP: %d", status)
+    }
+    if state := spectator.next("move").State; strings.Join(state.History, " ") != "e4 e5" {
+        t.Errorf("unexpected history after the HTTP move: %v", state.History)
+    }
+
+    //Reconnecting gets the current state again
+    spectator.close()
+    again, _ := dialWebSocket(t, httpServer, path, "")
+    if state := again.next("state").State; strings.Join(state.History, " ") != "e4 e5" {
+        t.Errorf("unexpected history on reconnect: %v", state.History)
+    }
+}
+
+func TestStreamClockAndFlagFall(t *testing.T) {
+    server := New()
+    server.clockInterval = 20 * time.Millisecond
+    httpServer := httptest.NewServer(server)
+    defer httpServer.Close()
+    created := createTestGame(t, httpServer, `{"time": "300ms"}`)
+
+    spectator, _ := dialWebSocket(t, httpServer, "/games/"+created.ID+"/ws", "")
+    spectator.next("state")
+
+    clocks := spectator.next("clock").Clocks
+    if clocks == nil || clocks.White >= 300 || clocks.Black != 300 {
+        t.Errorf("unexpected clocks, only White's should run: %+v", clocks)
+    }
+
+    outcome := spectator.next("outcome").State.Outcome
+    if outcome == nil || outcome.Result != "0-1" || outcome.Reason != "time forfeit" {
+        t.Errorf("unexpected outcome after the flag fall: %+v", outcome)
+    }
+}
+
+func TestStreamRemovedWhenClientsLeave(t *testing.T) {
+    server := New()
+    httpServer := httptest.NewServer(server)
+    defer httpServer.Close()
+    created := createTestGame(t, httpServer, "")
+
+    client, _ := dialWebSocket(t, httpServer, "/games/"+created.ID+"/ws", "")
+    client.next("state")
+    client.close()
+
+    for start := time.Now(); time.Since(start) < 5*time.Second; time.Sleep(10 * time.Millisecond) {
+        server.mutex.Lock()
+        streams := len(server.streams)
+        server.mutex.Unlock()
+        if streams == 0 {
+            return
+        }
+    }
+    t.Error("stream kept after its last client left")
+}
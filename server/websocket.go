@@ -0,0 +1,188 @@
+package server
+
+import (
+    "bufio"
+    "crypto/sha1"
+    "encoding/base64"
+    "encoding/binary"
+    "errors"
+    "io"
+    "net"
+    "net/http"
+    "strings"
+    "sync"
+)
+
+// MARK: wsConn (minimal WebSocket connection, RFC 6455: text messages, ping and close, no extensions)
+const (
+    wsGUID           = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+    wsMaxMessageSize = 1 << 16
+
+    wsContinuation = 0x0
+    wsText         = 0x1
+    wsBinary       = 0x2
+    wsClose        = 0x8
+    wsPing         = 0x9
+    wsPong         = 0xA
+)
+
+type wsConn struct {
+    conn       net.Conn
+    reader     *bufio.Reader
+    writeMutex sync.Mutex //frames are written by the reading and the sending goroutines
+}
+
+// Answer the opening handshake and take over the connection
+func upgradeWebSocket(writer http.ResponseWriter, request *http.Request) (*wsConn, error) {
+    if !headerContains(request.Header, "Connection", "upgrade") || !headerContains(request.Header, "Upgrade", "websocket") {
+        return nil, errors.New("not a WebSocket request")
+    }
+    if request.Header.Get("Sec-WebSocket-Version") != "13" {
+        return nil, errors.New("unsupported WebSocket version")
+    }
+    key := request.Header.Get("Sec-WebSocket-Key")
+    if key == "" {
+        return nil, errors.New("missing Sec-WebSocket-Key")
+    }
+
+    hijacker, ok := writer.(http.Hijacker)
+    if !ok {
+        return nil, errors.New("connection cannot be taken over")
+    }
+    conn, buffer, err := hijacker.Hijack()
+    if err != nil {
+        return nil, err
+    }
+
+    hash := sha1.Sum([]byte(key + wsGUID))
+    response := "HTTP/1.1 101 Switching Protocols\r\n" +
+        "Upgrade: websocket\r\n" +
+        "Connection: Upgrade\r\n" +
+        "Sec-WebSocket-Accept: " + base64.StdEncoding.EncodeToString(hash[:]) + "\r\n\r\n"
+    if _, err := conn.Write([]byte(response)); err != nil {
+        conn.Close()
+        return nil, err
+    }
+    return &wsConn{conn: conn, reader: buffer.Reader}, nil
+}
+
+// Read the next text message, answering pings on the way. Returns io.EOF when the client closes the connection.
+func (ws *wsConn) readMessage() ([]byte, error) {
+    var message []byte
+    for {
+        final, opcode, payload, err := ws.readFrame()
+        if err != nil {
+            return nil, err
+        }
+
+        switch opcode {
+        case wsPing:
+            if err := ws.writeFrame(wsPong, payload); err != nil {
+                return nil, err
+            }
+        case wsPong:
+        case wsClose:
+            ws.writeFrame(wsClose, nil)
+            return nil, io.EOF
+        case wsText, wsBinary, wsContinuation:
+            message = append(message, payload...)
+            if len(message) > wsMaxMessageSize {
+                return nil, errors.New("message too large")
+            }
+            if final {
+                return message, nil
+            }
+        default:
+            return nil, errors.New("unknown opcode")
+        }
+    }
+}
+
+func (ws *wsConn) readFrame() (final bool, opcode byte, payload []byte, err error) {
+    var header [2]byte
+    if _, err = io.ReadFull(ws.reader, header[:]); err != nil {
+        return
+    }
+    final = header[0]&0x80 != 0
+    opcode = header[0] & 0x0F
+    masked := header[1]&0x80 != 0
+
+    length := uint64(header[1] & 0x7F)
+    switch length {
+    case 126:
+        var extended [2]byte
+        if _, err = io.ReadFull(ws.reader, extended[:]); err != nil {
+            return
+        }
+        length = uint64(binary.BigEndian.Uint16(extended[:]))
+    case 127:
+        var extended [8]byte
+        if _, err = io.ReadFull(ws.reader, extended[:]); err != nil {
+            return
+        }
+        length = binary.BigEndian.Uint64(extended[:])
+    }
+    if length > wsMaxMessageSize {
+        err = errors.New("frame too large")
+        return
+    }
+
+    //Clients must mask every frame
+    if !masked {
+        err = errors.New("unmasked client frame")
+        return
+    }
+    var mask [4]byte
+    if _, err = io.ReadFull(ws.reader, mask[:]); err != nil {
+        return
+    }
+    payload = make([]byte, length)
+    if _, err = io.ReadFull(ws.reader, payload); err != nil {
+        return
+    }
+    for i := range payload {
+        payload[i] ^= mask[i%4]
+    }
+    return
+}
+
+func (ws *wsConn) writeMessage(message []byte) error {
+    return ws.writeFrame(wsText, message)
+}
+
+// Write a single unmasked frame, servers never mask
+func (ws *wsConn) writeFrame(opcode byte, payload []byte) error {
+    ws.writeMutex.Lock()
+    defer ws.writeMutex.Unlock()
+
+    frame := []byte{0x80 | opcode}
+    length := len(payload)
+    switch {
+    case length < 126:
+        frame = append(frame, byte(length))
+    case length <= 0xFFFF:
+        frame = append(frame, 126, byte(length>>8), byte(length))
+    default:
+        frame = append(frame, 127)
+        frame = binary.BigEndian.AppendUint64(frame, uint64(length))
+    }
+    frame = append(frame, payload...)
+    _, err := ws.conn.Write(frame)
+    return err
+}
+
+func (ws *wsConn) close() {
+    ws.conn.Close()
+}
+
+// Check if a comma separated header has the token, ignoring case
+func headerContains(header http.Header, name, token string) bool {
+    for _, value := range header[name] {
+        for _, field := range strings.Split(value, ",") {
+            if strings.EqualFold(strings.TrimSpace(field), token) {
+                return true
+            }
+        }
+    }
+    return false
+}
@@ -0,0 +1,230 @@
+package utils
+
+import (
+    "bytes"
+    "fmt"
+    "os"
+    "strconv"
+)
+
+// Highlight of a square on the board
+type Highlight int
+const (
+    NoHighlight       Highlight = iota
+    LastMoveHighlight Highlight = iota
+    CheckHighlight    Highlight = iota
+    SelectedHighlight Highlight = iota //selected piece
+    TargetHighlight   Highlight = iota //squares the selected piece can move to
+)
+
+// MARK: Cell (content of one square to render)
+type Cell struct {
+    Symbol    string //chess glyph, "" for an empty square
+    Letter    string //ASCII letter used instead of the glyph, e.g. "N" for a white Knight and "n" for a black one
+    White     bool   //piece color, used for the foreground color
+    Highlight Highlight
+}
+
+// MARK: Theme (ANSI 256-color codes of the colored board)
+type Theme struct {
+    Name                             string
+    LightSquare, DarkSquare          int
+    LastMove, Check, Selected, Target int
+    WhitePiece, BlackPiece           int
+}
+
+var Themes = []Theme{
+    {"brown", 180, 137, 143, 160, 67, 109, 231, 16},
+    {"green", 151, 65, 143, 160, 67, 109, 231, 16},
+    {"blue", 110, 67, 143, 160, 172, 179, 231, 16},
+    {"gray", 248, 242, 143, 160, 67, 109, 231, 16},
+}
+
+// Get the theme with the given name
+func FindTheme(name string) (Theme, bool) {
+    for _, theme := range Themes {
+        if theme.Name == name {
+            return theme, true
+        }
+    }
+    return Theme{}, false
+}
+
+// Check if the file is a terminal, colors are only written to terminals
+func IsTerminal(file *os.File) bool {
+    info, err := file.Stat()
+    return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// MARK: Renderer (draws the board as text, with or without ANSI colors)
+type Renderer struct {
+    Colored bool //draw squares with ANSI background colors instead of "_ |" cells
+    ASCII   bool //draw letters instead of chess glyphs
+    Flipped bool //draw from Black's side: rank 1 at the top and file h on the left
+    Theme   Theme
+}
+
+// Render the cells, row 0 being rank 8 and column 0 being file a
+func (renderer Renderer) Render(cells [][]Cell) string {
+    if renderer.Colored {
+        if renderer.Theme.Name == "" {
+            renderer.Theme = Themes[0]
+        }
+        return renderer.renderColored(cells)
+    }
+    return renderer.renderPlain(cells)
+}
+
+func (renderer Renderer) renderPlain(cells [][]Cell) string {
+
+    row := len(cells)
+    col := len(cells[0])
+
+    var buffer bytes.Buffer
+
+    ranks, files := renderer.getDisplayOrder(row, col)
+
+    //Print top column letters
+    renderer.writeColumnLetters(&buffer, files, "   ", " ", "  ")
+
+    //Print each row
+    for n, i := range ranks {
+        //Print left row numbers following with " |"
+        buffer.WriteString(strconv.Itoa(i + 1)) //has to convert string for WriteString() method
+        buffer.WriteString(" |")
+
+        //Print each square in same row
+        for _, j := range files {
+            buffer.WriteString(renderer.stringifySquare(cells[-i+row-1][j]))
+        }
+
+        //Print right row numbers
+        buffer.WriteString(" " + strconv.Itoa(i + 1)) //has to convert string for WriteString() method
+        buffer.WriteString("\n")
+        if n != len(ranks)-1 {
+            buffer.WriteString("\n")
+        }
+
+    }
+
+    //Print bottom column letters
+    renderer.writeColumnLetters(&buffer, files, "   ", " ", "  ")
+
+    return buffer.String()
+}
+
+func (renderer Renderer) renderColored(cells [][]Cell) string {
+
+    row := len(cells)
+    col := len(cells[0])
+    theme := renderer.Theme
+
+    var buffer bytes.Buffer
+
+    ranks, files := renderer.getDisplayOrder(row, col)
+
+    renderer.writeColumnLetters(&buffer, files, "  ", " ", " ")
+
+    for _, i := range ranks {
+        buffer.WriteString(strconv.Itoa(i + 1) + " ")
+
+        boardRow := -i + row - 1
+        for _, j := range files {
+            cell := cells[boardRow][j]
+
+            background := theme.DarkSquare
+            if (boardRow+j)%2 == 0 {
+                background = theme.LightSquare //a8 and h1 are light squares
+            }
+            switch cell.Highlight {
+            case LastMoveHighlight:
+                background = theme.LastMove
+            case CheckHighlight:
+                background = theme.Check
+            case SelectedHighlight:
+                background = theme.Selected
+            case TargetHighlight:
+                background = theme.Target
+            }
+
+            foreground := theme.BlackPiece
+            if cell.White {
+                foreground = theme.WhitePiece
+            }
+
+            symbol := renderer.getSymbol(cell)
+            if symbol == "" {
+                symbol = " "
+            } else if !renderer.ASCII {
+                symbol = solidGlyph(symbol) //colors tell the sides apart, outlined glyphs are hard to see on a background
+            }
+            buffer.WriteString(fmt.Sprintf("\x1b[48;5;%dm\x1b[1;38;5;%dm %s \x1b[0m", background, foreground, symbol))
+        }
+
+        buffer.WriteString(" " + strconv.Itoa(i + 1) + "\n")
+    }
+
+    renderer.writeColumnLetters(&buffer, files, "  ", " ", " ")
+
+    return buffer.String()
+}
+
+// Get the ranks (0 for rank 1) from top to bottom and the files (0 for file a) from left to right
+func (renderer Renderer) getDisplayOrder(row, col int) ([]int, []int) {
+    ranks := make([]int, row)
+    for n := range ranks {
+        ranks[n] = row - 1 - n
+        if renderer.Flipped {
+            ranks[n] = n
+        }
+    }
+    files := make([]int, col)
+    for n := range files {
+        files[n] = n
+        if renderer.Flipped {
+            files[n] = col - 1 - n
+        }
+    }
+    return ranks, files
+}
+
+func (renderer Renderer) writeColumnLetters(buffer *bytes.Buffer, files []int, padding, before, after string) {
+    buffer.WriteString(padding) //padding for row numbers
+    for _, i := range files {
+        colLetter := (string)(rune('a' + i))
+        buffer.WriteString(before + colLetter + after)
+    }
+    buffer.WriteString("\n")
+}
+
+func (renderer Renderer) stringifySquare(cell Cell) string {
+    sq := renderer.getSymbol(cell)
+    if len(sq) == 0 {
+        sq = "_"
+    }
+    switch cell.Highlight {
+    case LastMoveHighlight:
+        return "[" + sq + "]|"
+    case CheckHighlight:
+        return "!" + sq + "!|"
+    case SelectedHighlight, TargetHighlight:
+        return "(" + sq + ")|"
+    }
+    return " " + sq + " |"
+}
+
+func (renderer Renderer) getSymbol(cell Cell) string {
+    if renderer.ASCII && cell.Letter != "" {
+        return cell.Letter
+    }
+    return cell.Symbol
+}
+
+// Get the filled glyph (♚-♟) for an outlined White glyph (♔-♙)
+func solidGlyph(symbol string) string {
+    runes := []rune(symbol)
+    if len(runes) == 1 && runes[0] >= '\u2654' && runes[0] <= '\u2659' {
+        return string(runes[0] + 6)
+    }
+    return symbol
+}
@@ -0,0 +1,119 @@
+package utils
+
+import (
+    "strconv"
+    "testing"
+)
+
+// Kings on e1 and e8, a White Pawn that just moved from e2 to e4
+func newTestCells() [][]Cell {
+    cells := make([][]Cell, 8)
+    for i := range cells {
+        cells[i] = make([]Cell, 8)
+    }
+    cells[0][4] = Cell{Symbol: "♚", Letter: "k"}
+    cells[7][4] = Cell{Symbol: "♔", Letter: "K", White: true}
+    cells[4][4] = Cell{Symbol: "♙", Letter: "P", White: true, Highlight: LastMoveHighlight}
+    cells[6][4] = Cell{Highlight: LastMoveHighlight}
+    return cells
+}
+
+func TestRenderPlain(t *testing.T) {
+    //Same layout as the board before the Renderer, "[ ]" around the squares of the last move
+    expected := `    a   b   c   d   e   f   g   h  
+8 | _ | _ | _ | _ | ♚ | _ | _ | _ | 8
+
+7 | _ | _ | _ | _ | _ | _ | _ | _ | 7
+
+6 | _ | _ | _ | _ | _ | _ | _ | _ | 6
+
+5 | _ | _ | _ | _ | _ | _ | _ | _ | 5
+
+4 | _ | _ | _ | _ |[♙]| _ | _ | _ | 4
+
+3 | _ | _ | _ | _ | _ | _ | _ | _ | 3
+
+2 | _ | _ | _ | _ |[_]| _ | _ | _ | 2
+
+1 | _ | _ | _ | _ | ♔ | _ | _ | _ | 1
+    a   b   c   d   e   f   g   h  
+`
+    if rendered := (Renderer{}).Render(newTestCells()); rendered != expected {
+        t.Errorf("plain board:\n%s\nexpected:\n%s", rendered, expected)
+    }
+}
+
+func TestRenderASCIIFlipped(t *testing.T) {
+    expected := `    h   g   f   e   d   c   b   a  
+1 | _ | _ | _ | K | _ | _ | _ | _ | 1
+
+2 | _ | _ | _ |[_]| _ | _ | _ | _ | 2
+
+3 | _ | _ | _ | _ | _ | _ | _ | _ | 3
+
+4 | _ | _ | _ |[P]| _ | _ | _ | _ | 4
+
+5 | _ | _ | _ | _ | _ | _ | _ | _ | 5
+
+6 | _ | _ | _ | _ | _ | _ | _ | _ | 6
+
+7 | _ | _ | _ | _ | _ | _ | _ | _ | 7
+
+8 | _ | _ | _ | k | _ | _ | _ | _ | 8
+    h   g   f   e   d   c   b   a  
+`
+    if rendered := (Renderer{ASCII: true, Flipped: true}).Render(newTestCells()); rendered != expected {
+        t.Errorf("flipped ASCII board:\n%s\nexpected:\n%s", rendered, expected)
+    }
+}
+
+func TestRenderHighlightsPlain(t *testing.T) {
+    cells := [][]Cell{
+        {{Symbol: "♚", Highlight: CheckHighlight}, {Highlight: SelectedHighlight}},
+        {{Highlight: TargetHighlight}, {}},
+    }
+    expected := "    a   b  \n2 |!♚!|(_)| 2\n\n1 |(_)| _ | 1\n    a   b  \n"
+    if rendered := (Renderer{}).Render(cells); rendered != expected {
+        t.Errorf("highlights: %q, expected %q", rendered, expected)
+    }
+}
+
+func TestRenderColored(t *testing.T) {
+    cells := [][]Cell{
+        {{Symbol: "♔", Letter: "K", White: true}, {}},
+        {{Highlight: LastMoveHighlight}, {Symbol: "♚", Letter: "k", Highlight: CheckHighlight}},
+    }
+    square := func(background, foreground int, symbol string) string {
+        return "\x1b[48;5;" + strconv.Itoa(background) + "m\x1b[1;38;5;" + strconv.Itoa(foreground) + "m " + symbol + " \x1b[0m"
+    }
+
+    //Without a theme the first one is used, brown: light 180, dark 137, last move 143, check 160, pieces 231 and 16.
+    //White's outlined glyph is drawn filled, the foreground color tells the sides apart.
+    expected := "   a  b \n" +
+        "2 " + square(180, 231, "♚") + square(137, 16, " ") + " 2\n" +
+        "1 " + square(143, 16, " ") + square(160, 16, "♚") + " 1\n" +
+        "   a  b \n"
+    if rendered := (Renderer{Colored: true}).Render(cells); rendered != expected {
+        t.Errorf("colored board: %q, expected %q", rendered, expected)
+    }
+
+    blue, _ := FindTheme("blue")
+    expected = "   b  a \n" +
+        "1 " + square(160, 16, "k") + square(143, 16, " ") + " 1\n" +
+        "2 " + square(67, 16, " ") + square(110, 231, "K") + " 2\n" +
+        "   b  a \n"
+    if rendered := (Renderer{Colored: true, ASCII: true, Flipped: true, Theme: blue}).Render(cells); rendered != expected {
+        t.Errorf("flipped ASCII board in blue: %q, expected %q", rendered, expected)
+    }
+}
+
+func TestFindTheme(t *testing.T) {
+    for _, theme := range Themes {
+        if found, ok := FindTheme(theme.Name); !ok || found != theme {
+            t.Errorf("theme %s not found", theme.Name)
+        }
+    }
+    if _, ok := FindTheme("purple"); ok {
+        t.Error("unknown theme found")
+    }
+}
@@ -2,72 +2,12 @@ package utils
 
 import (
     "bytes"
-    "strconv"
     "os"
     "bufio"
     "strings"
 )
 
 // MARK: Static methods of Utils class
-func StringifyBoard(board [][]string) string {
-
-    row := len(board)
-    col := len(board[0])
-
-    var buffer bytes.Buffer
-
-    //Print top column letters
-    for i := 0; i < col; i++ {
-        if i == 0 {
-            buffer.WriteString("   ") //padding for col letters
-        }
-        colLetter := (string)('a' + i)
-        buffer.WriteString(" " + colLetter + "  ")
-    }
-    buffer.WriteString("\n")
-
-    //Print each row
-    for i := row - 1; i >= 0; i-- {
-        //Print left row numbers following with " |"
-        buffer.WriteString(strconv.Itoa(i + 1)) //has to convert string for WriteString() method
-        buffer.WriteString(" |")
-
-        //Print each square in same row
-        for j := 0; j < col; j++ {
-            buffer.WriteString(stringifySquare(board[-i+len(board)-1][j]))
-        }
-
-        //Print right row numbers
-        buffer.WriteString(" " + strconv.Itoa(i + 1)) //has to convert string for WriteString() method
-        buffer.WriteString("\n")
-        if i != 0 {
-            buffer.WriteString("\n")
-        }
-
-    }
-
-    //Print bottom column letters
-    for i := 0; i < col; i++ {
-        if i == 0 {
-            buffer.WriteString("   ") //padding for col letters
-        }
-        colLetter := (string)('a' + i)
-        buffer.WriteString(" " + colLetter + "  ")
-    }
-
-    buffer.WriteString("\n")
-
-    return buffer.String()
-}
-
-func stringifySquare(sq string) string {
-    if len(sq) == 0 {
-        return " _ |"
-    } else {
-        return " " + sq + " |"
-    }
-}
-
 func ParseTestCase(path string) TestCase {
     file, err := os.Open(path)
 